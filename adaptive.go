@@ -0,0 +1,188 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+const (
+	// learnIntervals is how many of the most recent intervals are used
+	// to learn from.
+	learnIntervals = 10
+	// otherSeasonWeight is the weight of intervals from a different
+	// season than the current one.
+	otherSeasonWeight = 0.25
+	// minDiverging is how many intervals in a row need to be outside of
+	// the configured interval before a change is suggested.
+	minDiverging = 3
+)
+
+// scheduled returns the window in which the task of the given kind is
+// due next, counting from when it has last been done or skipped. For
+// plants with adaptive watering, it is derived from the intervals at
+// which they have actually been watered. Checks after the last watering
+// and snoozes postpone it. Watering is aligned to the preferred watering
+// days.
+func (p Plant) scheduled(sched *Scheduler, kind eventKind) (window, bool) {
+	w, ok := p.unaligned(sched, kind)
+	if ok && kind == eventWatered {
+		w = sched.aligned(w)
+	}
+	return w, ok
+}
+
+// unaligned returns the window of the task before it is aligned to the
+// preferred watering days.
+func (p Plant) unaligned(sched *Scheduler, kind eventKind) (window, bool) {
+	from, snoozes := p.deferrals(sched, kind)
+	return p.snoozedFrom(sched, kind, from, snoozes)
+}
+
+// scheduledFrom returns the window of the task as if it had last been
+// done at from, without any snoozes.
+func (p Plant) scheduledFrom(sched *Scheduler, kind eventKind, from time.Time) (window, bool) {
+	intervals := p.intervals(kind)
+	w, ok := sched.scheduledIn(from, intervals)
+	if kind != eventWatered {
+		return w, ok
+	}
+
+	if learned, adaptive := p.adaptiveInterval(sched, timeNow()); adaptive && !from.IsZero() {
+		w, ok = windowAfter(from, learned), true
+	}
+	if check, checked := p.checkSinceWatering(); ok && checked {
+		postponed := windowAfter(check, dayRange{Min: sched.checkPostpone, Max: sched.checkPostpone})
+		if postponed.Opens > w.Opens {
+			w.Opens = postponed.Opens
+		}
+		if postponed.Closes > w.Closes {
+			w.Closes = postponed.Closes
+		}
+	}
+	return w, ok
+}
+
+// adaptiveInterval returns the watering interval learned from the recent
+// waterings, with the tolerance of the interval configured for at. It's
+// not ok if the plant doesn't use adaptive watering, doesn't need water
+// at that time or there's too little history.
+func (p Plant) adaptiveInterval(sched *Scheduler, at time.Time) (dayRange, bool) {
+	current := sched.interval(p.WateringIntervals, at)
+	if !p.AdaptiveWatering || current.Max == 0 {
+		return dayRange{}, false
+	}
+	days, learned := sched.learnedInterval(p.wateringIntervals(current), at)
+	if !learned {
+		return dayRange{}, false
+	}
+	// keep the tolerance of the configured interval, but don't let the
+	// window open before the last watering.
+	width := current.Max - current.Min
+	min := int(math.Round(days)) - width/2
+	max := min + width
+	if min < 0 {
+		min = 0
+	}
+	return dayRange{Min: min, Max: max}, true
+}
+
+// checkSinceWatering returns the last check after the last watering.
+func (p Plant) checkSinceWatering() (time.Time, bool) {
+	check := last(p.times(eventChecked))
+	if check.IsZero() || !check.After(last(p.times(eventWatered))) {
+		return time.Time{}, false
+	}
+	return check, true
+}
+
+// interval is the time between two waterings.
+type interval struct {
+	days float64
+	end  time.Time
+}
+
+// wateringIntervals returns the most recent intervals between waterings,
+// the most recent last. If a check since the last watering showed that
+// the soil is still moist after more than the current interval, that's
+// counted as an interval as well, as the actual one is at least as long.
+func (p Plant) wateringIntervals(current dayRange) []interval {
+	times := p.times(eventWatered)
+	start := 0
+	if len(times) > learnIntervals+1 {
+		start = len(times) - learnIntervals - 1
+	}
+	var intervals []interval
+	for i := start; i < len(times)-1; i++ {
+		intervals = append(intervals, interval{days: float64(daysBetween(times[i], times[i+1])), end: times[i+1]})
+	}
+	if check, ok := p.checkSinceWatering(); ok {
+		days := float64(daysBetween(last(times), check))
+		if int(days) > current.Max {
+			intervals = append(intervals, interval{days: days, end: check})
+		}
+	}
+	return intervals
+}
+
+// learnedInterval returns the weighted average of the intervals.
+// Intervals in the season of now count fully, the ones from other
+// seasons less. It's not ok if there is too little history.
+func (s *Scheduler) learnedInterval(intervals []interval, now time.Time) (float64, bool) {
+	var sum, weights float64
+	for _, i := range intervals {
+		w := 1.0
+		if s.seasonAt(i.end) != s.seasonAt(now) {
+			w = otherSeasonWeight
+		}
+		sum += i.days * w
+		weights += w
+	}
+	if weights < 2 {
+		return 0, false
+	}
+	return sum / weights, true
+}
+
+// intervalSuggestion suggests to change the configured watering interval
+// of the current season if the plant has been watered outside of it
+// consistently. It's empty if there's nothing to suggest.
+func (p Plant) intervalSuggestion(sched *Scheduler) string {
+	now := timeNow()
+	current := sched.interval(p.WateringIntervals, now)
+	if current.Max == 0 {
+		return ""
+	}
+
+	// the most recent intervals of this season, as long as they are all
+	// too short or all too long.
+	intervals := p.wateringIntervals(current)
+	var diverging []float64
+	var direction int
+	for i := len(intervals) - 1; i >= 0 && sched.seasonAt(intervals[i].end) == sched.seasonAt(now); i-- {
+		d := int(math.Round(intervals[i].days))
+		dir := 0
+		if d < current.Min {
+			dir = -1
+		} else if d > current.Max {
+			dir = 1
+		}
+		if dir == 0 || (direction != 0 && dir != direction) {
+			break
+		}
+		direction = dir
+		diverging = append(diverging, intervals[i].days)
+	}
+	if len(diverging) < minDiverging {
+		return ""
+	}
+
+	var sum float64
+	for _, d := range diverging {
+		sum += d
+	}
+	avg := int(math.Round(sum / float64(len(diverging))))
+	return fmt.Sprintf("Watered every ~%d days lately, consider changing the %s interval from %s to %d",
+		avg, strings.ToLower(sched.intervalName(p.WateringIntervals, now)), current, avg)
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLearnedInterval(t *testing.T) {
+	sched, err := newScheduler(defaultConfig().Seasons)
+	if err != nil {
+		t.Fatal(err)
+	}
+	summer := time.Date(2024, 6, 15, 12, 0, 0, 0, time.Local)
+	winter := time.Date(2024, 1, 15, 12, 0, 0, 0, time.Local)
+
+	for _, tt := range []struct {
+		name      string
+		intervals []interval
+		want      float64
+		ok        bool
+	}{
+		{name: "no history"},
+		{name: "single interval", intervals: []interval{{days: 7, end: summer}}},
+		{
+			name:      "same season",
+			intervals: []interval{{days: 6, end: summer}, {days: 8, end: summer}},
+			want:      7, ok: true,
+		},
+		{
+			name: "other season weighs less",
+			intervals: []interval{
+				{days: 14, end: winter}, {days: 14, end: winter},
+				{days: 6, end: summer}, {days: 8, end: summer},
+			},
+			want: 8.4, ok: true,
+		},
+		{
+			name: "only other season",
+			intervals: []interval{
+				{days: 14, end: winter}, {days: 14, end: winter},
+				{days: 14, end: winter}, {days: 14, end: winter},
+			},
+		},
+	} {
+		got, ok := sched.learnedInterval(tt.intervals, summer)
+		if ok != tt.ok || math.Abs(got-tt.want) > 1e-9 {
+			t.Fatalf("%s: expected=%v (%v), got=%v (%v)", tt.name, tt.want, tt.ok, got, ok)
+		}
+	}
+}
+
+func TestAdaptiveIntervalOpensAfterWatering(t *testing.T) {
+	sched, err := newScheduler(defaultConfig().Seasons)
+	if err != nil {
+		t.Fatal(err)
+	}
+	intervals, err := parseSeasonalIntervals("1-9")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.Local)
+	p := Plant{WateringIntervals: intervals, AdaptiveWatering: true}
+	for i := 0; i < 4; i++ {
+		p.History = append(p.History, CareEvent{Kind: eventWatered, Time: now.AddDate(0, 0, 2*(i-3))})
+	}
+	want := dayRange{Min: 0, Max: 6}
+	if got, ok := p.adaptiveInterval(sched, now); !ok || got != want {
+		t.Fatalf("expected=%v, got=%v (%v)", want, got, ok)
+	}
+}
+
+func TestIntervalSuggestion(t *testing.T) {
+	t.Cleanup(func() { asOf = time.Time{} })
+	sched, err := newScheduler(defaultConfig().Seasons)
+	if err != nil {
+		t.Fatal(err)
+	}
+	intervals, err := parseSeasonalIntervals("5-7/14")
+	if err != nil {
+		t.Fatal(err)
+	}
+	asOf = time.Date(2024, 6, 15, 0, 0, 0, 0, time.Local)
+
+	for _, tt := range []struct {
+		name string
+		// days between the waterings, the most recent last.
+		days []int
+		want string
+	}{
+		{name: "within the interval", days: []int{6, 6, 6}},
+		{name: "too few diverging", days: []int{6, 10, 10}},
+		{name: "too long", days: []int{10, 10, 10}, want: "~10 days lately, consider changing the summer interval from 5-7 to 10"},
+		{name: "too short", days: []int{3, 3, 4}, want: "~3 days lately, consider changing the summer interval from 5-7 to 3"},
+		{name: "mixed directions", days: []int{10, 3, 10, 10}},
+	} {
+		watered := timeNow()
+		history := []CareEvent{{Kind: eventWatered, Time: watered}}
+		for i := len(tt.days) - 1; i >= 0; i-- {
+			watered = watered.AddDate(0, 0, -tt.days[i])
+			history = append([]CareEvent{{Kind: eventWatered, Time: watered}}, history...)
+		}
+		p := Plant{WateringIntervals: intervals, History: history}
+		got := p.intervalSuggestion(sched)
+		if (tt.want == "") != (got == "") || !strings.HasSuffix(got, tt.want) {
+			t.Fatalf("%s: expected=%q, got=%q", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestCheckPostponesWatering(t *testing.T) {
+	t.Cleanup(func() { asOf = time.Time{} })
+	sched, err := newScheduler(defaultConfig().Seasons)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sched.checkPostpone = 3
+	// all days are counted from Jun 1, which is today + today.
+	const today = 20
+	base := time.Date(2024, 6, 1, 12, 0, 0, 0, time.Local)
+	asOf = base.AddDate(0, 0, today)
+
+	for _, tt := range []struct {
+		name      string
+		intervals string
+		checked   int
+		want      window
+	}{
+		{name: "before the window", intervals: "7", checked: 2, want: window{Opens: 7, Closes: 7}},
+		{name: "on the due day", intervals: "7", checked: 6, want: window{Opens: 9, Closes: 9}},
+		{name: "when overdue", intervals: "7", checked: 12, want: window{Opens: 15, Closes: 15}},
+		{name: "within a window", intervals: "5-8", checked: 6, want: window{Opens: 9, Closes: 9}},
+		{name: "before the last watering", intervals: "7", checked: -1, want: window{Opens: 7, Closes: 7}},
+	} {
+		intervals, err := parseSeasonalIntervals(tt.intervals)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p := Plant{WateringIntervals: intervals}
+		p.appendEvent(CareEvent{Kind: eventWatered, Time: base})
+		p.appendEvent(CareEvent{Kind: eventChecked, Time: base.AddDate(0, 0, tt.checked)})
+		want := window{Opens: tt.want.Opens - today, Closes: tt.want.Closes - today}
+		if got, ok := p.scheduled(sched, eventWatered); !ok || got != want {
+			t.Fatalf("%s: expected=%+v, got=%+v (%v)", tt.name, want, got, ok)
+		}
+	}
+}
+
+func TestChecksAreLearnedFrom(t *testing.T) {
+	t.Cleanup(func() { asOf = time.Time{} })
+	sched, err := newScheduler(defaultConfig().Seasons)
+	if err != nil {
+		t.Fatal(err)
+	}
+	base := time.Date(2024, 6, 1, 12, 0, 0, 0, time.Local)
+	asOf = base.AddDate(0, 0, 30)
+	intervals, err := parseSeasonalIntervals("5-7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	current := dayRange{Min: 5, Max: 7}
+	days := func(intervals []interval) []float64 {
+		var days []float64
+		for _, i := range intervals {
+			days = append(days, i.days)
+		}
+		return days
+	}
+
+	// watered every 10 days, the soil was still moist 10 days later.
+	p := Plant{WateringIntervals: intervals, AdaptiveWatering: true}
+	for _, day := range []int{0, 10, 20} {
+		p.appendEvent(CareEvent{Kind: eventWatered, Time: base.AddDate(0, 0, day)})
+	}
+	if got := days(p.wateringIntervals(current)); len(got) != 2 {
+		t.Fatalf("expected only the intervals between waterings, got %v", got)
+	}
+	if s := p.intervalSuggestion(sched); s != "" {
+		t.Fatalf("expected no suggestion after two intervals, got %q", s)
+	}
+	// a check within the interval says nothing about its length.
+	p.appendEvent(CareEvent{Kind: eventChecked, Time: base.AddDate(0, 0, 25)})
+	if got := days(p.wateringIntervals(current)); len(got) != 2 {
+		t.Fatalf("expected the check within the interval to be ignored, got %v", got)
+	}
+
+	p.appendEvent(CareEvent{Kind: eventChecked, Time: base.AddDate(0, 0, 30)})
+	if got := days(p.wateringIntervals(current)); len(got) != 3 || got[2] != 10 {
+		t.Fatalf("expected the check to count as an interval of 10 days, got %v", got)
+	}
+	if got, ok := p.adaptiveInterval(sched, timeNow()); !ok || got != (dayRange{Min: 9, Max: 11}) {
+		t.Fatalf("expected the check to be learned from, got %v (%v)", got, ok)
+	}
+	if s := p.intervalSuggestion(sched); !strings.HasSuffix(s, "~10 days lately, consider changing the summer interval from 5-7 to 10") {
+		t.Fatalf("expected a suggestion including the check, got %q", s)
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// parseWeekday parses the name of a weekday, e.g. "mon" or "Monday".
+func parseWeekday(s string) (time.Weekday, error) {
+	name := strings.ToLower(strings.TrimSpace(s))
+	if len(name) >= 3 {
+		for d := time.Sunday; d <= time.Saturday; d++ {
+			if strings.HasPrefix(strings.ToLower(d.String()), name) {
+				return d, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("unknown weekday %q", s)
+}
+
+// parseWeekdays parses all of the weekdays in names, in which each entry
+// may also be a comma-separated list such as "mon,thu".
+func parseWeekdays(names []string) ([]time.Weekday, error) {
+	var days []time.Weekday
+	for _, n := range names {
+		for _, s := range strings.Split(n, ",") {
+			d, err := parseWeekday(s)
+			if err != nil {
+				return nil, err
+			}
+			days = append(days, d)
+		}
+	}
+	return days, nil
+}
+
+// preferred returns true if d is one of the preferred watering days.
+func (s *Scheduler) preferred(d time.Weekday) bool {
+	for _, p := range s.wateringDays {
+		if p == d {
+			return true
+		}
+	}
+	return false
+}
+
+// aligned moves the watering window w to the first preferred watering
+// day in it, so that plants that can wait are watered together. A window
+// that is open already stays open, only its end is moved. Windows without
+// a preferred day, and overdue ones, are left as they are. So are fixed
+// intervals such as 7, their window is a single day that only matches if
+// it already is a preferred one.
+func (s *Scheduler) aligned(w window) window {
+	if len(s.wateringDays) == 0 || w.Closes < 0 {
+		return w
+	}
+	from := w.Opens
+	if from < 0 {
+		from = 0
+	}
+	now := timeNow()
+	for day := from; day <= w.Closes; day++ {
+		if !s.preferred(now.AddDate(0, 0, day).Weekday()) {
+			continue
+		}
+		if w.open() {
+			return window{Opens: w.Opens, Closes: day}
+		}
+		return window{Opens: day, Closes: day}
+	}
+	return w
+}
+
+// previewAlignment shows how the watering schedule of each plant shifts
+// when it is aligned to the configured or the given watering days.
+func previewAlignment(pDB *PlantDB, args []string) error {
+	fs := flag.NewFlagSet("align", flag.ContinueOnError)
+	sched := *pDB.sched
+	fs.Func("days", "preferred watering days to preview instead of the configured ones, e.g. mon,thu", func(s string) error {
+		days, err := parseWeekdays([]string{s})
+		sched.wateringDays = days
+		return err
+	})
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if len(sched.wateringDays) == 0 {
+		return fmt.Errorf("no watering days configured, set watering_days in the config or pass -days")
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tINTERVAL\tNEXT WATERING\tALIGNED")
+	for _, p := range pDB.Plants {
+		if p.archived() {
+			continue
+		}
+		unaligned, ok := p.unaligned(&sched, eventWatered)
+		if !ok {
+			fmt.Fprintf(w, "%s\t%s\tunknown\t-\n", p.Name, sched.interval(p.WateringIntervals, timeNow()))
+			continue
+		}
+		aligned := "unchanged"
+		if a := sched.aligned(unaligned); a != unaligned {
+			aligned = a.String() + " (" + timeNow().AddDate(0, 0, a.Closes).Format("Mon") + ")"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
+			p.Name, sched.interval(p.WateringIntervals, timeNow()), unaligned, aligned)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAligned(t *testing.T) {
+	t.Cleanup(func() { asOf = time.Time{} })
+	asOf = time.Date(2024, 6, 15, 0, 0, 0, 0, time.Local) // a Saturday
+	days, err := parseWeekdays([]string{"mon,thu"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sched := &Scheduler{wateringDays: days}
+
+	for _, tt := range []struct {
+		w, want window
+	}{
+		// open windows stay open until the preferred day.
+		{w: window{Opens: 0, Closes: 3}, want: window{Opens: 0, Closes: 2}},
+		{w: window{Opens: -1, Closes: 5}, want: window{Opens: -1, Closes: 2}},
+		{w: window{Opens: 1, Closes: 3}, want: window{Opens: 2, Closes: 2}},
+		{w: window{Opens: 4, Closes: 6}, want: window{Opens: 5, Closes: 5}},
+		// open since Thursday, but the next preferred day is after it
+		// closes.
+		{w: window{Opens: -2, Closes: 1}, want: window{Opens: -2, Closes: 1}},
+		{w: window{Opens: -3, Closes: -1}, want: window{Opens: -3, Closes: -1}},
+		// fixed intervals stay on their day.
+		{w: window{Opens: 3, Closes: 3}, want: window{Opens: 3, Closes: 3}},
+		{w: window{Opens: 2, Closes: 2}, want: window{Opens: 2, Closes: 2}},
+	} {
+		if got := sched.aligned(tt.w); got != tt.want {
+			t.Fatalf("%+v: expected=%+v, got=%+v", tt.w, tt.want, got)
+		}
+	}
+
+	// a plant that can be watered keeps being shown as such.
+	intervals, err := parseSeasonalIntervals("5-8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &Plant{
+		Name:              "Fred",
+		WateringIntervals: intervals,
+		History:           []CareEvent{{Kind: eventWatered, Time: asOf.AddDate(0, 0, -6)}},
+	}
+	items := (&PlantDB{sched: sched, Plants: []*Plant{p}}).Items()
+	if d := items[0].(plantItem).Description(); !strings.HasSuffix(d, "can water") {
+		t.Fatalf("expected Fred to be open for watering, got %q", d)
+	}
+
+	if got := (&Scheduler{}).aligned(window{Opens: 0, Closes: 3}); got != (window{Opens: 0, Closes: 3}) {
+		t.Fatalf("expected no alignment without watering days, got=%+v", got)
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// archived reports whether the plant has died or has been given away.
+// Archived plants keep their history, but are hidden from the list.
+func (p Plant) archived() bool {
+	return p.ArchivedAt != nil
+}
+
+func (pDB *PlantDB) archive(p *Plant, at time.Time, reason string) {
+	p.ArchivedAt = &at
+	p.ArchiveReason = reason
+}
+
+func (pDB *PlantDB) restore(p *Plant) {
+	p.ArchivedAt = nil
+	p.ArchiveReason = ""
+}
+
+// remove deletes the plant and its whole history.
+func (pDB *PlantDB) remove(p *Plant) {
+	for i := range pDB.Plants {
+		if pDB.Plants[i] == p {
+			pDB.Plants = append(pDB.Plants[:i], pDB.Plants[i+1:]...)
+			return
+		}
+	}
+}
+
+type NoArchivedPlantsEntry struct{}
+
+func (NoArchivedPlantsEntry) FilterValue() string {
+	return ""
+}
+func (NoArchivedPlantsEntry) Title() string {
+	return "The trash is empty!"
+}
+func (NoArchivedPlantsEntry) Description() string {
+	return "Long live your plants..."
+}
+
+// ArchivedItems returns the archived plants, most recently archived first.
+func (pDB *PlantDB) ArchivedItems() []list.Item {
+	var plants []*Plant
+	for _, p := range pDB.Plants {
+		if p.archived() {
+			plants = append(plants, p)
+		}
+	}
+	if len(plants) == 0 {
+		return []list.Item{NoArchivedPlantsEntry{}}
+	}
+
+	sort.SliceStable(plants, func(i, j int) bool {
+		return plants[i].ArchivedAt.After(*plants[j].ArchivedAt)
+	})
+	items := make([]list.Item, 0, len(plants))
+	for _, p := range plants {
+		items = append(items, p)
+	}
+	return items
+}
+
+func newArchivePrompt(pDB *PlantDB, plant *Plant, done func()) *inputPrompt {
+	date := newDateInput("Date", "YYYY-MM-DD")
+	reason := newTextInput("Reason", "died, given away, ...")
+	reason.Focus()
+	reason.PromptStyle = focusedStyle
+	reason.TextStyle = focusedStyle
+	return &inputPrompt{
+		inputs:     []textinput.Model{date, reason},
+		focusIndex: 1,
+		title:      "Archive " + plant.Name,
+		confirmAction: func(ip *inputPrompt) (tea.Model, error) {
+			date, err := parseInputDate(ip.inputs[0].Value())
+			if err != nil {
+				// should already be verified by the Validate action on the input.
+				return nil, fmt.Errorf("invalid date: %v", err)
+			}
+
+			pDB.archive(plant, date, ip.inputs[1].Value())
+			done()
+			return nil, nil
+		},
+	}
+}
+
+// confirmPrompt asks a yes / no question.
+type confirmPrompt struct {
+	title    string
+	question string
+	confirm  func()
+}
+
+func (cp *confirmPrompt) Init() tea.Cmd { return nil }
+
+func (cp *confirmPrompt) View() string {
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(cp.title) + ":\n\n")
+	b.WriteString(cp.question + "\n\n")
+	b.WriteString("y - yes, n - no\n")
+	return b.String()
+}
+
+func (cp *confirmPrompt) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return cp, nil
+	}
+
+	switch keyMsg.String() {
+	case "ctrl+c":
+		return cp, tea.Quit
+	case "y", "Y":
+		cp.confirm()
+		return nil, nil
+	case "n", "N", "esc":
+		return nil, nil
+	}
+	return cp, nil
+}
+
+func newDeletePrompt(pDB *PlantDB, plant *Plant, done func()) *confirmPrompt {
+	return &confirmPrompt{
+		title: "Delete " + plant.Name,
+		question: "This deletes the plant and its whole history.\n" +
+			"To keep the history, archive it instead.\n\n" +
+			"Are you sure?",
+		confirm: func() {
+			pDB.remove(plant)
+			done()
+		},
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestArchive(t *testing.T) {
+	t.Cleanup(func() { asOf = time.Time{} })
+	sched, err := newScheduler(defaultConfig().Seasons)
+	if err != nil {
+		t.Fatal(err)
+	}
+	asOf = time.Date(2024, 6, 15, 0, 0, 0, 0, time.Local)
+	watering, err := parseSeasonalIntervals("7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	watered := []CareEvent{{Kind: eventWatered, Time: time.Date(2024, 6, 1, 12, 0, 0, 0, time.Local)}}
+	fred := &Plant{ID: "0000fred", Name: "Fred", WateringIntervals: watering, History: watered}
+	bob := &Plant{ID: "00000bob", Name: "Bob", WateringIntervals: watering, History: watered}
+	cleo := &Plant{ID: "0000cleo", Name: "Cleo", WateringIntervals: watering, History: watered}
+	pDB := &PlantDB{sched: sched, Plants: []*Plant{fred, bob, cleo}}
+	names := func(items []list.Item) []string {
+		var names []string
+		for _, item := range items {
+			if i, ok := item.(interface{ Title() string }); ok {
+				names = append(names, i.Title())
+			}
+		}
+		return names
+	}
+
+	called := false
+	ip := newArchivePrompt(pDB, fred, func() { called = true })
+	ip.inputs[0].SetValue("2024-06-10")
+	ip.inputs[1].SetValue("given away")
+	if _, err := ip.confirmAction(ip); err != nil {
+		t.Fatal(err)
+	}
+	if !called || !fred.archived() || fred.ArchiveReason != "given away" || fred.ArchivedAt.Format("2006-01-02") != "2024-06-10" {
+		t.Fatalf("Fred has not been archived: %+v", fred)
+	}
+	pDB.archive(bob, time.Date(2024, 6, 12, 0, 0, 0, 0, time.Local), "died")
+
+	if got := names(pDB.Items()); len(got) != 1 || got[0] != "Cleo" {
+		t.Fatalf("expected only Cleo in the list, got %v", got)
+	}
+	if got := names(pDB.ArchivedItems()); len(got) != 2 || got[0] != "Bob" || got[1] != "Fred" {
+		t.Fatalf("expected the most recently archived plant first, got %v", got)
+	}
+	for _, e := range pDB.dueReport(0) {
+		if e.Plant != "Cleo" {
+			t.Fatalf("expected archived plants not to be due, got %+v", e)
+		}
+	}
+
+	pDB.restore(bob)
+	if bob.archived() || bob.ArchiveReason != "" {
+		t.Fatalf("Bob has not been restored: %+v", bob)
+	}
+	if got := names(pDB.Items()); len(got) != 2 {
+		t.Fatalf("expected Bob to be back in the list, got %v", got)
+	}
+
+	dp := newDeletePrompt(pDB, fred, func() {})
+	if m, _ := dp.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")}); m != nil || len(pDB.Plants) != 3 {
+		t.Fatal("expected nothing to be deleted without confirming")
+	}
+	dp.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	if len(pDB.Plants) != 2 || pDB.Plants[0] != bob || pDB.Plants[1] != cleo {
+		t.Fatalf("Fred has not been deleted: %+v", pDB.Plants)
+	}
+	if got := names(pDB.ArchivedItems()); len(got) != 1 || got[0] != "The trash is empty!" {
+		t.Fatalf("expected the trash to be empty, got %v", got)
+	}
+}
+
+func TestEventCommandsRejectArchivedPlants(t *testing.T) {
+	sched, err := newScheduler(defaultConfig().Seasons)
+	if err != nil {
+		t.Fatal(err)
+	}
+	location := filepath.Join(t.TempDir(), "plants.json")
+	pDB, err := openDB(location, sched)
+	if err != nil {
+		t.Fatal(err)
+	}
+	archivedAt := time.Date(2024, 6, 1, 0, 0, 0, 0, time.Local)
+	pDB.Plants = []*Plant{{
+		ID: "0000fred", Name: "Fred", ArchivedAt: &archivedAt,
+		Reminders: []Reminder{{Text: "mist", Due: archivedAt}},
+	}}
+	if err := pDB.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, args := range [][]string{
+		{"water", "Fred"},
+		{"snooze", "water", "Fred"},
+		{"remind", "Fred", "3d", "mist"},
+		{"complete", "Fred"},
+	} {
+		if code := runCommand(pDB, args); code != 1 {
+			t.Fatalf("%v: expected exit code 1, got %d", args, code)
+		}
+		if pDB, err = openDB(location, sched); err != nil {
+			t.Fatal(err)
+		}
+	}
+	defer pDB.Close()
+	p := pDB.Plants[0]
+	if len(p.History) != 0 || len(p.Reminders) != 1 || p.Reminders[0].done() {
+		t.Fatalf("archived plant has been changed: %+v", p)
+	}
+	// showing it is still fine.
+	if _, err := pDB.findPlant("Fred"); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// asOf is the day that is treated as today, e.g. to preview what's due
+// next week or to backfill forgotten events. It's zero for the actual
+// day.
+var asOf time.Time
+
+// timeNow is used instead of time.Now by everything that depends on the
+// day. With asOf set, it returns the current time of day on that day, so
+// that events added on it are still ordered.
+func timeNow() time.Time {
+	now := time.Now()
+	if asOf.IsZero() {
+		return now
+	}
+	y, m, d := asOf.Date()
+	return time.Date(y, m, d, now.Hour(), now.Minute(), now.Second(), now.Nanosecond(), now.Location())
+}
+
+// parseAsOf parses the day to act on. Besides the dates and durations
+// accepted by parseRelativeDate, which may be negative, it accepts a
+// weekday for the next one of it, and "today" or nothing for the actual
+// day. Everything is relative to the day currently acted on.
+func parseAsOf(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "today" {
+		return time.Time{}, nil
+	}
+	if d, err := parseWeekday(s); err == nil {
+		today := startOfDay(timeNow())
+		return today.AddDate(0, 0, (int(d)-int(today.Weekday())+7)%7), nil
+	}
+	day, err := parseRelativeDate(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected a date (YYYY-MM-DD), a weekday or a duration like 3d, -1w or 2m")
+	}
+	if daysFromToday(day) == 0 && asOf.IsZero() {
+		return time.Time{}, nil
+	}
+	return day, nil
+}
+
+// asOfStatus describes the day that is acted on, it's empty for the
+// actual day.
+func asOfStatus() string {
+	if asOf.IsZero() {
+		return ""
+	}
+	return "as of " + asOf.Format("Mon, 2006-01-02")
+}
+
+// newAsOfPrompt asks for the day to act on, onChange is called after it
+// has been changed.
+func newAsOfPrompt(onChange func()) *inputPrompt {
+	day := newTextInput("As of", "YYYY-MM-DD, sat, 3d, -1w, today")
+	if !asOf.IsZero() {
+		day.SetValue(asOf.Format("2006-01-02"))
+	}
+	day.Focus()
+	day.PromptStyle = focusedStyle
+	day.TextStyle = focusedStyle
+	return &inputPrompt{
+		inputs: []textinput.Model{day},
+		title:  "Act As Of Another Day",
+		confirmAction: func(ip *inputPrompt) (tea.Model, error) {
+			t, err := parseAsOf(ip.inputs[0].Value())
+			if err != nil {
+				return nil, err
+			}
+			asOf = t
+			onChange()
+			return nil, nil
+		},
+	}
+}
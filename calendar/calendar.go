@@ -45,7 +45,7 @@ type Event struct {
 	Style lipgloss.Style
 }
 
-func NewRender(events ...Event) string {
+func NewRender(today time.Time, events ...Event) string {
 	const monthsDisplayed = 3
 	var (
 		weekdays = []Weekday{
@@ -64,12 +64,38 @@ func NewRender(events ...Event) string {
 	)
 	// Each month will have their days represented in a string array
 	calendarMonthRender := make([][]string, monthsDisplayed)
-	now := time.Now()
+	now := today
+
+	// the months shown, relative to the current one. Upcoming events,
+	// e.g. reminders, are shown by including the months up to the one of
+	// the earliest of them instead of the earliest months. If that's too
+	// far ahead, it's shown after the current and the next month.
+	months := make([]int, monthsDisplayed)
+	for i := range months {
+		months[i] = (i + 1) - monthsDisplayed
+	}
+	ahead := 0
+	for _, e := range events {
+		m := (e.Time.Year()-now.Year())*12 + int(e.Time.Month()) - int(now.Month())
+		if m > 0 && (ahead == 0 || m < ahead) {
+			ahead = m
+		}
+	}
+	for i := range months {
+		if ahead < monthsDisplayed {
+			months[i] += ahead
+		} else {
+			months[i] += monthsDisplayed - 1
+		}
+	}
+	if ahead >= monthsDisplayed {
+		months[monthsDisplayed-1] = ahead
+	}
 
 	for monthIndex := range calendarMonthRender {
 		firstDayOfMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
 
-		monthRelativePosition := (monthIndex + 1) - len(calendarMonthRender)
+		monthRelativePosition := months[monthIndex]
 		firstDayOfMonth = firstDayOfMonth.AddDate(0, monthRelativePosition, 0)
 		lastDayOfMonth := firstDayOfMonth.AddDate(0, 1, -1)
 
@@ -157,7 +183,7 @@ func NewRender(events ...Event) string {
 	return strings.TrimSpace(s)
 }
 
-func Render(times []time.Time) string {
+func Render(today time.Time, times []time.Time) string {
 	const monthsDisplayed = 3
 	var (
 		weekdays = []Weekday{
@@ -176,7 +202,7 @@ func Render(times []time.Time) string {
 	)
 	// Each month will have their days represented in a string array
 	calendarMonthRender := make([][]string, monthsDisplayed)
-	now := time.Now()
+	now := today
 
 	for monthIndex := range calendarMonthRender {
 		firstDayOfMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
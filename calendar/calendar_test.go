@@ -0,0 +1,33 @@
+package calendar
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestNewRenderShowsUpcomingMonths(t *testing.T) {
+	today := time.Date(2024, 1, 15, 0, 0, 0, 0, time.Local)
+	for _, tt := range []struct {
+		ahead  int
+		months []string
+	}{
+		{0, []string{"November", "December", "January"}},
+		{1, []string{"December", "January", "February"}},
+		{2, []string{"January", "February", "March"}},
+		{5, []string{"January", "February", "June"}},
+	} {
+		var events []Event
+		if tt.ahead > 0 {
+			events = append(events, Event{Time: today.AddDate(0, tt.ahead, 0), Style: lipgloss.NewStyle()})
+		}
+		out := NewRender(today, events...)
+		for _, m := range tt.months {
+			if !strings.Contains(out, m) {
+				t.Fatalf("%d months ahead: %s is not shown:\n%s", tt.ahead, m, out)
+			}
+		}
+	}
+}
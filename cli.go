@@ -0,0 +1,488 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// command is a non-interactive subcommand that operates on the PlantDB.
+type command struct {
+	name string
+	args string
+	help string
+	run  func(pDB *PlantDB, args []string) error
+	// readOnly commands do not write the DB back to disk.
+	readOnly bool
+}
+
+var commands []command
+
+func init() {
+	// initialised here as usage refers back to commands.
+	commands = []command{
+		{name: "list", help: "list all plants", run: listPlants, readOnly: true},
+		{name: "show", args: "<plant>", help: "show details of a plant", run: showPlant, readOnly: true},
+		{name: "due", args: "[-within days] [-format text|json|tsv]", help: "report plants that need care", run: duePlants, readOnly: true},
+		{name: "forecast", args: "[-weeks n] [-by day|week] [-list] [-format text|json]", help: "forecast the workload of the coming weeks", run: forecastPlants, readOnly: true},
+		{name: "align", args: "[-days mon,thu]", help: "preview how watering shifts to the preferred watering days (fixed intervals are never moved)", run: previewAlignment, readOnly: true},
+		{name: "water", args: "<plant> [date] [event flags]", help: "add / remove a watering event", run: waterPlant},
+		{name: "check", args: "<plant> [date] [event flags]", help: "add / remove a check that showed the soil is still moist", run: checkPlant},
+		{name: "fertilize", args: "<plant> [date] [type] [event flags]", help: "add / remove a fertilization event", run: fertilizePlant},
+		{name: "repot", args: "<plant> [date] [size] [event flags]", help: "add / remove a repotting event", run: repotPlant},
+		{name: "do", args: "<task> <plant> [date] [event flags]", help: "add / remove an event of a custom task", run: doTask},
+		{name: "snooze", args: "<task> <plant> [date] [-days n] [event flags]", help: "push a task back by some days", run: snoozeTask},
+		{name: "skip", args: "<task> <plant> [date] [event flags]", help: "skip the next occurrence of a task", run: skipTask},
+		{name: "remind", args: "<plant> <date|10d|2w|3m> <text>", help: "add a one-off reminder", run: remindPlant},
+		{name: "complete", args: "<plant> [text]", help: "complete the next open reminder (containing text)", run: completeReminder},
+		{name: "history", args: "[-plant plant] [-kind kind] [-since date] [-until date] [-format text|json]", help: "list past events", run: eventHistory, readOnly: true},
+		{name: "add", args: "[flags]", help: "add a new plant", run: addPlant},
+		{name: "edit", args: "<plant> [flags]", help: "edit an existing plant", run: editPlant},
+		{name: "help", help: "show this help", run: printUsage, readOnly: true},
+	}
+}
+
+func lookupCommand(name string) (command, bool) {
+	for _, c := range commands {
+		if c.name == name {
+			return c, true
+		}
+	}
+	return command{}, false
+}
+
+func printUsage(_ *PlantDB, _ []string) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Usage: positive-hydration [--config file] [--db location] [command]")
+	fmt.Fprintln(w, "\nWithout a command, the interactive UI is started.\n\nCommands:")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %s %s\t%s\n", c.name, c.args, c.help)
+	}
+	fmt.Fprintln(w, "\nEvent flags: -amount, -product, -actor and -note record details of the event,")
+	fmt.Fprintln(w, "fertilize also takes -dilution and -npk, repot -material and -soil.")
+	return w.Flush()
+}
+
+// findPlant looks up a plant by its ID or name. An ID or exact
+// (case-insensitive) name match is preferred, otherwise a unique partial
+// match is accepted.
+func (pDB *PlantDB) findPlant(name string) (*Plant, error) {
+	var exact, partial []*Plant
+	for _, p := range pDB.Plants {
+		switch {
+		case p.ID == strings.ToLower(name):
+			return p, nil
+		case strings.EqualFold(p.Name, name):
+			exact = append(exact, p)
+		case strings.Contains(strings.ToLower(p.Name), strings.ToLower(name)):
+			partial = append(partial, p)
+		}
+	}
+
+	matches := exact
+	if len(matches) == 0 {
+		matches = partial
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no plant named %q", name)
+	case 1:
+		return matches[0], nil
+	default:
+		names := make([]string, 0, len(matches))
+		for _, p := range matches {
+			names = append(names, p.Name+" ("+p.ID+")")
+		}
+		return nil, fmt.Errorf("%q is ambiguous, matches: %s", name, strings.Join(names, ", "))
+	}
+}
+
+// findActivePlant is findPlant for commands that add events or
+// reminders, which archived plants don't get anymore.
+func (pDB *PlantDB) findActivePlant(name string) (*Plant, error) {
+	p, err := pDB.findPlant(name)
+	if err != nil {
+		return nil, err
+	}
+	if p.archived() {
+		return nil, fmt.Errorf("%s has been archived, restore it first", p.Name)
+	}
+	return p, nil
+}
+
+// argOr returns args[i] if it is set, def otherwise.
+func argOr(args []string, i int, def string) string {
+	if i < len(args) && args[i] != "" {
+		return args[i]
+	}
+	return def
+}
+
+func listPlants(pDB *PlantDB, _ []string) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tLOCATION\tLAST WATERED\tNEXT WATERING\tNEXT FERTILIZING")
+	for _, item := range pDB.Items() {
+		p, ok := item.(plantItem)
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			p.ID, p.Name, p.Location,
+			formatTimeInDays(last(p.times(eventWatered))),
+			p.nextScheduledWateringDay(pDB.sched),
+			p.nextScheduledFertilizingDay(pDB.sched),
+		)
+	}
+	return w.Flush()
+}
+
+func showPlant(pDB *PlantDB, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: show <plant>")
+	}
+	p, err := pDB.findPlant(args[0])
+	if err != nil {
+		return err
+	}
+	fmt.Println(p.Render(pDB.sched, true))
+	return nil
+}
+
+// eventArgs parses the common "<plant> [date]" arguments and the detail
+// flags of the event commands, which may come in any order. The date
+// defaults to today. The positional arguments are returned as well.
+func (pDB *PlantDB) eventArgs(name string, kind eventKind, args []string, maxArgs int) (*Plant, CareEvent, []string, error) {
+	e := pDB.newEvent(kind, timeNow())
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.StringVar(&e.Amount, "amount", "", "how much, e.g. 500ml")
+	fs.StringVar(&e.Product, "product", "", "the product that has been used")
+	fs.StringVar(&e.Actor, "actor", e.Actor, "who did it")
+	fs.StringVar(&e.Note, "note", "", "a note, e.g. leaves drooping")
+	switch kind {
+	case eventFertilized:
+		fs.StringVar(&e.Dilution, "dilution", "", "the dilution, e.g. 1:100")
+		fs.StringVar(&e.NPK, "npk", "", "the NPK ratio, e.g. 7-3-6")
+	case eventRepotted:
+		fs.StringVar(&e.PotMaterial, "material", "", "the material of the new pot, e.g. terracotta")
+		fs.StringVar(&e.SoilMix, "soil", "", "the soil mix")
+	case eventSnoozed:
+		fs.IntVar(&e.Days, "days", 1, "by how many days")
+	}
+
+	var pos []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, e, nil, err
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		pos = append(pos, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+
+	if len(pos) < 1 || len(pos) > maxArgs {
+		c, _ := lookupCommand(name)
+		return nil, e, nil, fmt.Errorf("usage: %s %s", c.name, c.args)
+	}
+	p, err := pDB.findActivePlant(pos[0])
+	if err != nil {
+		return nil, e, nil, err
+	}
+	if len(pos) > 1 && pos[1] != "" {
+		e.Time, err = parseInputDate(pos[1])
+		if err != nil {
+			return nil, e, nil, fmt.Errorf("invalid date: %v", err)
+		}
+	}
+	return p, e, pos, nil
+}
+
+// toggle adds the event or removes the one of the same kind that
+// already exists on the same day, like Plant.toggleEvent. New events are
+// appended to the storage right away.
+func (pDB *PlantDB) toggle(p *Plant, e CareEvent) error {
+	date := e.Time.Format("2006-01-02")
+	if i := p.eventOn(e.Kind, e.Time); i >= 0 {
+		p.removeEvent(i)
+		fmt.Printf("%s: removed %s on %s\n", p.Name, e.Kind, date)
+		return nil
+	}
+
+	if err := pDB.addEvent(p, e); err != nil {
+		return err
+	}
+	fmt.Printf("%s: marked as %s on %s\n", p.Name, e.Kind, date)
+	return nil
+}
+
+func waterPlant(pDB *PlantDB, args []string) error {
+	p, e, _, err := pDB.eventArgs("water", eventWatered, args, 2)
+	if err != nil {
+		return err
+	}
+	return pDB.toggle(p, e)
+}
+
+func checkPlant(pDB *PlantDB, args []string) error {
+	p, e, _, err := pDB.eventArgs("check", eventChecked, args, 2)
+	if err != nil {
+		return err
+	}
+	return pDB.toggle(p, e)
+}
+
+func fertilizePlant(pDB *PlantDB, args []string) error {
+	p, e, pos, err := pDB.eventArgs("fertilize", eventFertilized, args, 3)
+	if err != nil {
+		return err
+	}
+	if e.Fertilizer, err = parseFertilizerType(argOr(pos, 2, "")); err != nil {
+		return err
+	}
+	// default to what has been used last time.
+	if last, ok := p.lastEvent(eventFertilized); ok && e.Fertilizer == "" {
+		e.Fertilizer = last.Fertilizer
+	}
+	return pDB.toggle(p, e)
+}
+
+func repotPlant(pDB *PlantDB, args []string) error {
+	p, e, pos, err := pDB.eventArgs("repot", eventRepotted, args, 3)
+	if err != nil {
+		return err
+	}
+	if s := argOr(pos, 2, ""); s != "" {
+		e.PotSize, err = strconv.Atoi(s)
+		if err != nil {
+			return fmt.Errorf("invalid pot size: %w", err)
+		}
+	}
+	return pDB.toggle(p, e)
+}
+
+func doTask(pDB *PlantDB, args []string) error {
+	if len(args) < 1 || strings.HasPrefix(args[0], "-") {
+		return fmt.Errorf("usage: do <task> <plant> [date] [event flags]")
+	}
+	t, ok := lookupTask(args[0])
+	if !ok {
+		return fmt.Errorf("unknown task %q, custom tasks are configured in the config file", args[0])
+	}
+	p, e, _, err := pDB.eventArgs("do", t.kind, args[1:], 2)
+	if err != nil {
+		return err
+	}
+	return pDB.toggle(p, e)
+}
+
+func snoozeTask(pDB *PlantDB, args []string) error {
+	return deferTask(pDB, "snooze", eventSnoozed, args)
+}
+
+func skipTask(pDB *PlantDB, args []string) error {
+	return deferTask(pDB, "skip", eventSkipped, args)
+}
+
+// deferTask adds a snooze or skip of the task in args[0]. Unlike other
+// events, they are never toggled, as a task can be deferred several times
+// a day.
+func deferTask(pDB *PlantDB, name string, kind eventKind, args []string) error {
+	if len(args) < 1 || strings.HasPrefix(args[0], "-") {
+		c, _ := lookupCommand(name)
+		return fmt.Errorf("usage: %s %s", c.name, c.args)
+	}
+	task, err := parseTask(args[0])
+	if err != nil {
+		return err
+	}
+	p, e, _, err := pDB.eventArgs(name, kind, args[1:], 2)
+	if err != nil {
+		return err
+	}
+	if e.Days < 1 && kind == eventSnoozed {
+		return fmt.Errorf("days need to be a positive number")
+	}
+	e.Task = task
+	if err := pDB.addEvent(p, e); err != nil {
+		return err
+	}
+	if w, ok := p.scheduled(pDB.sched, task); ok {
+		fmt.Printf("%s: %s %s, next %s\n", p.Name, kind, taskName(task), w)
+	} else {
+		fmt.Printf("%s: %s %s\n", p.Name, kind, taskName(task))
+	}
+	return nil
+}
+
+func remindPlant(pDB *PlantDB, args []string) error {
+	if len(args) < 3 {
+		return fmt.Errorf("usage: remind <plant> <date|10d|2w|3m> <text>")
+	}
+	p, err := pDB.findActivePlant(args[0])
+	if err != nil {
+		return err
+	}
+	due, err := parseReminderDate(args[1])
+	if err != nil {
+		return fmt.Errorf("invalid date: %v", err)
+	}
+	text := strings.Join(args[2:], " ")
+	p.addReminder(due, text)
+	fmt.Printf("%s: reminder %q on %s\n", p.Name, text, due.Format("2006-01-02"))
+	return nil
+}
+
+func completeReminder(pDB *PlantDB, args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("usage: complete <plant> [text]")
+	}
+	p, err := pDB.findActivePlant(args[0])
+	if err != nil {
+		return err
+	}
+	i := p.nextReminder(strings.Join(args[1:], " "))
+	if i < 0 {
+		return fmt.Errorf("%s has no matching open reminder", p.Name)
+	}
+	p.completeReminder(i, timeNow())
+	fmt.Printf("%s: completed %q\n", p.Name, p.Reminders[i].Text)
+	return nil
+}
+
+func eventHistory(pDB *PlantDB, args []string) error {
+	var q eventQuery
+	fs := flag.NewFlagSet("history", flag.ContinueOnError)
+	fs.Func("plant", "only list events of the plant with this ID or name", func(s string) error {
+		p, err := pDB.findPlant(s)
+		if err != nil {
+			return err
+		}
+		q.PlantID = p.ID
+		return nil
+	})
+	fs.Func("kind", "only list events of this kind (snoozed, skipped, checked, watered, fertilized, repotted or a custom task)", func(s string) error {
+		for _, kind := range allEventKinds() {
+			if string(kind) == s {
+				q.Kind = kind
+				return nil
+			}
+		}
+		return fmt.Errorf("unknown event kind %q", s)
+	})
+	fs.Func("since", "only list events on or after this date", func(s string) (err error) {
+		q.Since, err = parseInputDate(s)
+		return err
+	})
+	fs.Func("until", "only list events before this date", func(s string) (err error) {
+		q.Until, err = parseInputDate(s)
+		return err
+	})
+	format := fs.String("format", "text", "output format: text or json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	records, err := pDB.storage.Query(q)
+	if err != nil {
+		return err
+	}
+
+	switch *format {
+	case "text":
+		for _, r := range records {
+			line := fmt.Sprintf("%s  %-10s  %s", r.Time.Format("2006-01-02"), r.Kind, r.Plant)
+			if details := r.details(); details != "" {
+				line += "  (" + details + ")"
+			}
+			fmt.Println(line)
+		}
+		return nil
+	case "json":
+		if records == nil {
+			records = []eventRecord{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(records)
+	default:
+		return fmt.Errorf("unknown format %q", *format)
+	}
+}
+
+// plantFlags returns a FlagSet that writes the parsed values directly
+// into p, mirroring the fields of Plant.Prompt.
+func plantFlags(name string, p *Plant) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.StringVar(&p.Name, "name", p.Name, "plant name")
+	fs.StringVar(&p.Variety, "variety", p.Variety, "variety")
+	fs.StringVar(&p.Location, "location", p.Location, "location")
+	fs.IntVar(&p.WetSoilDepth, "wet-soil-depth", p.WetSoilDepth, "wet soil depth in cm")
+	fs.Func("watering", "watering intervals (summer/winter, spring/summer/autumn/winter or 12 months)", func(s string) (err error) {
+		p.WateringIntervals, err = parseSeasonalIntervals(s)
+		return err
+	})
+	fs.BoolVar(&p.AdaptiveWatering, "adaptive", p.AdaptiveWatering, "schedule watering by the recent actual intervals")
+	fs.Func("fertilizing", "fertilizing intervals (summer/winter, spring/summer/autumn/winter or 12 months)", func(s string) (err error) {
+		p.FertilizingIntervals, err = parseSeasonalIntervals(s)
+		return err
+	})
+	fs.Func("interval", "intervals of a custom task as task=summer/winter, empty for the default", func(s string) error {
+		name, intervals, ok := strings.Cut(s, "=")
+		if !ok {
+			return fmt.Errorf("expected task=intervals")
+		}
+		t, ok := lookupTask(name)
+		if !ok {
+			return fmt.Errorf("unknown task %q", name)
+		}
+		return p.setTaskIntervals(t.kind, intervals)
+	})
+	fs.IntVar(&p.PotSize, "pot-size", p.PotSize, "pot size in cm")
+	fs.Func("light-level", "0 - direct, 1 - bright, 2 - semi-shaded, 3 - shaded", func(s string) (err error) {
+		p.LightLevel, err = parseLightLevel(s)
+		return err
+	})
+	fs.StringVar(&p.SourcedFrom, "sourced-from", p.SourcedFrom, "where the plant is from")
+	fs.StringVar(&p.Comments, "comments", p.Comments, "comments")
+	return fs
+}
+
+func addPlant(pDB *PlantDB, args []string) error {
+	p := new(Plant)
+	if err := plantFlags("add", p).Parse(args); err != nil {
+		return err
+	}
+	if p.Name == "" {
+		return fmt.Errorf("name cannot be empty!")
+	}
+	pDB.add(p)
+	fmt.Printf("added %s (%s)\n", p.Name, p.ID)
+	return nil
+}
+
+func editPlant(pDB *PlantDB, args []string) error {
+	if len(args) < 1 || strings.HasPrefix(args[0], "-") {
+		return fmt.Errorf("usage: edit <plant> [flags]")
+	}
+	p, err := pDB.findPlant(args[0])
+	if err != nil {
+		return err
+	}
+	edited := *p
+	// the flags change the intervals of custom tasks in place.
+	edited.TaskIntervals = p.Clone().TaskIntervals
+	if err := plantFlags("edit", &edited).Parse(args[1:]); err != nil {
+		return err
+	}
+	if edited.Name == "" {
+		return fmt.Errorf("name cannot be empty!")
+	}
+	*p = edited
+	fmt.Printf("updated %s\n", p.Name)
+	return nil
+}
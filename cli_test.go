@@ -0,0 +1,38 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestRepotCommandSavesPotSize(t *testing.T) {
+	for _, name := range []string{"plants.json", "plants.db"} {
+		t.Run(name, func(t *testing.T) {
+			location := filepath.Join(t.TempDir(), name)
+			pDB, err := openDB(location, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			pDB.Plants = []*Plant{{ID: "0000fred", Name: "Fred", PotSize: 12}}
+			if err := pDB.Save(); err != nil {
+				t.Fatal(err)
+			}
+			if code := runCommand(pDB, []string{"repot", "Fred", "2024-06-01", "18"}); code != 0 {
+				t.Fatalf("repot failed with exit code %d", code)
+			}
+
+			pDB, err = openDB(location, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer pDB.Close()
+			p := pDB.Plants[0]
+			if p.PotSize != 18 {
+				t.Fatalf("pot size has not been saved. expected=18, got=%d", p.PotSize)
+			}
+			if len(p.History) != 1 || p.History[0].FromPotSize != 12 || p.History[0].PotSize != 18 {
+				t.Fatalf("wrong repotting event: %+v", p.History)
+			}
+		})
+	}
+}
@@ -0,0 +1,420 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Config is read once on startup from a TOML file. Everything that isn't
+// set in there keeps the value of defaultConfig.
+type Config struct {
+	// DB is the location of the DB, see openStorage.
+	DB string `toml:"db"`
+	// Actor is recorded as the one who did new care events, it defaults
+	// to the login name.
+	Actor   string  `toml:"actor"`
+	Seasons Seasons `toml:"seasons"`
+	Theme   Theme   `toml:"theme"`
+	Keys    Keys    `toml:"keys"`
+	Undo    Undo    `toml:"undo"`
+	// CheckPostpone is how many days watering is postponed after a check
+	// showed that the soil is still moist.
+	CheckPostpone int `toml:"check_postpone"`
+	// WateringDays are the preferred weekdays for watering, e.g. "mon"
+	// and "thu". Watering is moved to them when the plant tolerates it,
+	// i.e. the day is within its watering interval such as 5-8. Plants
+	// that can be watered already stay so until that day. Plants with a
+	// fixed interval such as 7 are never moved.
+	WateringDays []string `toml:"watering_days"`
+	// Tasks are care tasks in addition to watering, fertilizing and
+	// repotting.
+	Tasks []Task `toml:"tasks"`
+
+	// asOf is the day to act on instead of today, only set by flag.
+	asOf time.Time
+}
+
+// Task is a custom recurring care task, e.g. misting or rotating.
+type Task struct {
+	// Name is also the kind of the task's events, e.g. "misted".
+	Name string `toml:"name"`
+	// Keys toggle the task for today in the UI.
+	Keys  []string `toml:"keys"`
+	Color string   `toml:"color"`
+	// Intervals are the default intervals as summer/winter, plants can
+	// have their own.
+	Intervals string `toml:"intervals"`
+}
+
+// Undo configures the undo history of the UI.
+type Undo struct {
+	// Limit is the number of changes that can be undone.
+	Limit int `toml:"limit"`
+	// Persist keeps the history next to the DB, so that changes can
+	// still be undone after a restart.
+	Persist bool `toml:"persist"`
+}
+
+// Theme contains the colours of the UI, as accepted by lipgloss.Color.
+type Theme struct {
+	Focused    string `toml:"focused"`
+	Selected   string `toml:"selected"`
+	Border     string `toml:"border"`
+	Watered    string `toml:"watered"`
+	Fertilized string `toml:"fertilized"`
+	Repotted   string `toml:"repotted"`
+	Checked    string `toml:"checked"`
+	Reminder   string `toml:"reminder"`
+}
+
+// Keys contains the key bindings of the UI.
+type Keys struct {
+	Add       []string `toml:"add"`
+	Copy      []string `toml:"copy"`
+	Water     []string `toml:"water"`
+	WaterOn   []string `toml:"water_on"`
+	Check     []string `toml:"check"`
+	Fertilize []string `toml:"fertilize"`
+	Repot     []string `toml:"repot"`
+	Edit      []string `toml:"edit"`
+	Remind    []string `toml:"remind"`
+	Complete  []string `toml:"complete"`
+	Snooze    []string `toml:"snooze"`
+	Skip      []string `toml:"skip"`
+	Archive   []string `toml:"archive"`
+	Delete    []string `toml:"delete"`
+	Trash     []string `toml:"trash"`
+	Forecast  []string `toml:"forecast"`
+	AsOf      []string `toml:"as_of"`
+	Restore   []string `toml:"restore"`
+	Undo      []string `toml:"undo"`
+	Redo      []string `toml:"redo"`
+	Quit      []string `toml:"quit"`
+}
+
+func defaultConfig() Config {
+	return Config{
+		DB:    defaultDB(),
+		Actor: os.Getenv("USER"),
+		Seasons: Seasons{
+			DayLength: 11,
+		},
+		Theme: Theme{
+			Focused:    "205",
+			Selected:   "170",
+			Border:     "240",
+			Watered:    "#1d0ed1",
+			Fertilized: "#004b26",
+			Repotted:   "#512013",
+			Checked:    "#5f87af",
+			Reminder:   "#ff8700",
+		},
+		Keys: Keys{
+			Add:       []string{"a"},
+			Copy:      []string{"c"},
+			Water:     []string{"w"},
+			WaterOn:   []string{"W"},
+			Check:     []string{"m"},
+			Fertilize: []string{"f"},
+			Repot:     []string{"p"},
+			Edit:      []string{"e"},
+			Remind:    []string{"n"},
+			Complete:  []string{"N"},
+			Snooze:    []string{"z"},
+			Skip:      []string{"s"},
+			Archive:   []string{"x"},
+			Delete:    []string{"d"},
+			Trash:     []string{"t"},
+			Forecast:  []string{"F"},
+			AsOf:      []string{"T"},
+			Restore:   []string{"r"},
+			Undo:      []string{"u"},
+			Redo:      []string{"ctrl+r"},
+			Quit:      []string{"q"},
+		},
+		Undo: Undo{
+			Limit: 100,
+		},
+		CheckPostpone: 2,
+	}
+}
+
+// defaultDB returns the location of the DB in the config directory. DBs
+// that are still in ~/.config, where they were before XDG_CONFIG_HOME was
+// respected, keep being used.
+func defaultDB() string {
+	db := filepath.Join(configDir(), "positive_hydration.json")
+	if _, err := os.Stat(db); err == nil {
+		return db
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		legacy := filepath.Join(home, ".config", "positive_hydration.json")
+		if _, err := os.Stat(legacy); err == nil {
+			return legacy
+		}
+	}
+	return db
+}
+
+// configDir returns $XDG_CONFIG_HOME, falling back to ~/.config.
+func configDir() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return dir
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ".config"
+	}
+	return filepath.Join(home, ".config")
+}
+
+// loadConfig parses the global flags in args and loads the config. The
+// precedence is flags > environment > config file > defaults. It returns
+// the remaining arguments.
+func loadConfig(args []string) (Config, []string, error) {
+	fs := flag.NewFlagSet("positive-hydration", flag.ContinueOnError)
+	configFlag := fs.String("config", "", "location of the config file (env POSITIVE_HYDRATION_CONFIG)")
+	dbFlag := fs.String("db", "", "location of the DB (env POSITIVE_HYDRATION_DB)")
+	asOfFlag := fs.String("as-of", "", "act as if it were that day: YYYY-MM-DD, a weekday or e.g. 3d, -1w")
+	if err := fs.Parse(args); err != nil {
+		return Config{}, nil, err
+	}
+
+	cfg := defaultConfig()
+	location, explicit := *configFlag, true
+	if location == "" {
+		location = os.Getenv("POSITIVE_HYDRATION_CONFIG")
+	}
+	if location == "" {
+		location, explicit = filepath.Join(configDir(), "positive-hydration", "config.toml"), false
+	}
+
+	md, err := toml.DecodeFile(location, &cfg)
+	switch {
+	case os.IsNotExist(err) && !explicit:
+		// no config file is fine, but an explicitly given one must exist.
+	case err != nil:
+		return Config{}, nil, fmt.Errorf("could not read config file: %w", err)
+	case len(md.Undecoded()) > 0:
+		return Config{}, nil, fmt.Errorf("unknown config keys in %s: %v", location, md.Undecoded())
+	}
+
+	if db := os.Getenv("POSITIVE_HYDRATION_DB"); db != "" {
+		cfg.DB = db
+	}
+	if *dbFlag != "" {
+		cfg.DB = *dbFlag
+	}
+
+	if cfg.asOf, err = parseAsOf(*asOfFlag); err != nil {
+		return Config{}, nil, fmt.Errorf("invalid -as-of: %w", err)
+	}
+
+	if err := cfg.validate(); err != nil {
+		return Config{}, nil, fmt.Errorf("invalid config: %w", err)
+	}
+	return cfg, fs.Args(), nil
+}
+
+func (cfg Config) validate() error {
+	if _, _, err := cfg.Seasons.bounds(); err != nil {
+		return fmt.Errorf("seasons: %w", err)
+	}
+	if cfg.Undo.Limit < 0 {
+		return fmt.Errorf("undo: limit can't be negative")
+	}
+	if cfg.CheckPostpone < 0 {
+		return fmt.Errorf("check_postpone can't be negative")
+	}
+	if _, err := parseWeekdays(cfg.WateringDays); err != nil {
+		return fmt.Errorf("watering_days: %w", err)
+	}
+
+	taken := map[string]bool{}
+	for _, kind := range eventKinds {
+		taken[string(kind)] = true
+	}
+	bound := map[string]bool{}
+	for _, k := range newKeyMap(cfg.Keys, nil).all() {
+		bound[k] = true
+	}
+	for _, t := range cfg.Tasks {
+		switch {
+		case t.Name == "":
+			return fmt.Errorf("tasks: name can't be empty")
+		case taken[t.Name]:
+			return fmt.Errorf("tasks: %q is defined twice or is a builtin task", t.Name)
+		}
+		taken[t.Name] = true
+		if _, err := parseSeasonalIntervals(t.Intervals); t.Intervals != "" && err != nil {
+			return fmt.Errorf("tasks: %s: %w", t.Name, err)
+		}
+		for _, k := range t.Keys {
+			if bound[k] {
+				return fmt.Errorf("tasks: %s: key %q is bound already", t.Name, k)
+			}
+			bound[k] = true
+		}
+	}
+	return nil
+}
+
+// scheduler returns the scheduler for the seasons, which have been
+// validated already.
+func (cfg Config) scheduler() *Scheduler {
+	sched, _ := newScheduler(cfg.Seasons)
+	sched.checkPostpone = cfg.CheckPostpone
+	sched.wateringDays, _ = parseWeekdays(cfg.WateringDays)
+	return sched
+}
+
+// undoLocation returns where the undo history is persisted, or an empty
+// string if it isn't.
+func (cfg Config) undoLocation() string {
+	if !cfg.Undo.Persist {
+		return ""
+	}
+	return cfg.DB + ".undo"
+}
+
+var (
+	borderColor     = lipgloss.Color("240")
+	selectedColor   = lipgloss.Color("170")
+	wateredColor    = lipgloss.Color("#1d0ed1")
+	fertilizedColor = lipgloss.Color("#004b26")
+	repottedColor   = lipgloss.Color("#512013")
+	checkedColor    = lipgloss.Color("#5f87af")
+	reminderColor   = lipgloss.Color("#ff8700")
+)
+
+// applyTheme sets up the package-level styles with the colours of t.
+func applyTheme(t Theme) {
+	borderColor = lipgloss.Color(t.Border)
+	selectedColor = lipgloss.Color(t.Selected)
+	wateredColor = lipgloss.Color(t.Watered)
+	fertilizedColor = lipgloss.Color(t.Fertilized)
+	repottedColor = lipgloss.Color(t.Repotted)
+	checkedColor = lipgloss.Color(t.Checked)
+	reminderColor = lipgloss.Color(t.Reminder)
+
+	focusedStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(t.Focused))
+	blurredStyle = lipgloss.NewStyle().Foreground(borderColor)
+	cursorStyle = focusedStyle.Copy()
+	focusedButton = focusedStyle.Copy().Render("[ Submit ]")
+	blurredButton = fmt.Sprintf("[ %s ]", blurredStyle.Render("Submit"))
+	boxed = boxed.Copy().BorderForeground(borderColor)
+}
+
+// keyMap contains the key bindings of ShowPlants.
+type keyMap struct {
+	Add       key.Binding
+	Copy      key.Binding
+	Water     key.Binding
+	WaterOn   key.Binding
+	Check     key.Binding
+	Fertilize key.Binding
+	Repot     key.Binding
+	Edit      key.Binding
+	Remind    key.Binding
+	Complete  key.Binding
+	Snooze    key.Binding
+	Skip      key.Binding
+	Archive   key.Binding
+	Delete    key.Binding
+	Trash     key.Binding
+	Forecast  key.Binding
+	AsOf      key.Binding
+	Restore   key.Binding
+	Undo      key.Binding
+	Redo      key.Binding
+	Quit      key.Binding
+	// Tasks toggle the custom tasks, in the order of customTasks.
+	Tasks []key.Binding
+}
+
+func newKeyMap(k Keys, tasks []Task) keyMap {
+	binding := func(keys []string, help string) key.Binding {
+		return key.NewBinding(key.WithKeys(keys...), key.WithHelp(strings.Join(keys, "/"), help))
+	}
+	km := keyMap{
+		Add:       binding(k.Add, "add plant"),
+		Copy:      binding(k.Copy, "copy plant"),
+		Water:     binding(k.Water, "mark as watered"),
+		WaterOn:   binding(k.WaterOn, "mark as watered with specific date"),
+		Check:     binding(k.Check, "mark as checked, still moist"),
+		Fertilize: binding(k.Fertilize, "mark as fertilized"),
+		Repot:     binding(k.Repot, "mark as repotted"),
+		Edit:      binding(k.Edit, "edit plant"),
+		Remind:    binding(k.Remind, "add reminder"),
+		Complete:  binding(k.Complete, "complete next reminder"),
+		Snooze:    binding(k.Snooze, "snooze a task"),
+		Skip:      binding(k.Skip, "skip the next occurrence of a task"),
+		Archive:   binding(k.Archive, "archive plant"),
+		Delete:    binding(k.Delete, "delete plant"),
+		Trash:     binding(k.Trash, "show / hide archived plants"),
+		Forecast:  binding(k.Forecast, "show / hide workload forecast"),
+		AsOf:      binding(k.AsOf, "act as of another day"),
+		Restore:   binding(k.Restore, "restore archived plant"),
+		Undo:      binding(k.Undo, "undo last change"),
+		Redo:      binding(k.Redo, "redo last undone change"),
+		Quit:      binding(k.Quit, "quit"),
+	}
+	for _, t := range tasks {
+		km.Tasks = append(km.Tasks, binding(t.Keys, "mark as "+t.Name))
+	}
+	return km
+}
+
+// ShortHelp returns the bindings shown in the short help, with shorter
+// descriptions.
+func (km keyMap) ShortHelp() []key.Binding {
+	short := func(b key.Binding, help string) key.Binding {
+		return key.NewBinding(key.WithKeys(b.Keys()...), key.WithHelp(b.Help().Key, help))
+	}
+	return []key.Binding{
+		short(km.Add, "add"),
+		short(km.Water, "water"),
+		short(km.Fertilize, "fertilized"),
+		short(km.Repot, "repotted"),
+		short(km.Edit, "edit"),
+	}
+}
+
+func (km keyMap) FullHelp() []key.Binding {
+	return append([]key.Binding{
+		km.Add, km.Copy, km.Water, km.WaterOn, km.Check, km.Fertilize, km.Repot, km.Edit,
+		km.Remind, km.Complete, km.Snooze, km.Skip, km.Archive, km.Delete, km.Trash, km.Forecast, km.AsOf, km.Restore, km.Undo, km.Redo,
+	}, km.Tasks...)
+}
+
+// all returns all keys that are bound.
+func (km keyMap) all() []string {
+	var keys []string
+	for _, b := range append(km.FullHelp(), km.Quit) {
+		keys = append(keys, b.Keys()...)
+	}
+	return keys
+}
+
+// unbind removes the given keys from b.
+func unbind(b *key.Binding, keys []string) {
+	var remaining []string
+outer:
+	for _, k := range b.Keys() {
+		for _, remove := range keys {
+			if k == remove {
+				continue outer
+			}
+		}
+		remaining = append(remaining, k)
+	}
+	b.SetKeys(remaining...)
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// configEnv sets up an empty home and config directory for the rest of
+// the test and returns them.
+func configEnv(t *testing.T) (home, xdg string) {
+	t.Helper()
+	home = t.TempDir()
+	xdg = filepath.Join(home, "xdg")
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+	t.Setenv("POSITIVE_HYDRATION_CONFIG", "")
+	t.Setenv("POSITIVE_HYDRATION_DB", "")
+	return home, xdg
+}
+
+func writeFile(t *testing.T, name, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(name), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(name, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadConfigPrecedence(t *testing.T) {
+	_, xdg := configEnv(t)
+
+	cfg, args, err := loadConfig([]string{"list"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(xdg, "positive_hydration.json"); cfg.DB != want {
+		t.Fatalf("wrong default DB. expected=%s, got=%s", want, cfg.DB)
+	}
+	if !reflect.DeepEqual(args, []string{"list"}) {
+		t.Fatalf("wrong remaining args: %v", args)
+	}
+
+	writeFile(t, filepath.Join(xdg, "positive-hydration", "config.toml"), `db = "file.json"`)
+	other := filepath.Join(t.TempDir(), "other.toml")
+	writeFile(t, other, `db = "other.json"`)
+	for _, tt := range []struct {
+		name string
+		env  map[string]string
+		args []string
+		want string
+	}{
+		{name: "file", want: "file.json"},
+		{name: "config from env", env: map[string]string{"POSITIVE_HYDRATION_CONFIG": other}, want: "other.json"},
+		{name: "config from flag", args: []string{"-config", other}, want: "other.json"},
+		{name: "env", env: map[string]string{"POSITIVE_HYDRATION_DB": "env.json"}, want: "env.json"},
+		{name: "flag", env: map[string]string{"POSITIVE_HYDRATION_DB": "env.json"}, args: []string{"-db", "flag.json"}, want: "flag.json"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			cfg, _, err := loadConfig(tt.args)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.DB != tt.want {
+				t.Fatalf("expected=%s, got=%s", tt.want, cfg.DB)
+			}
+		})
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	_, xdg := configEnv(t)
+	for _, tt := range []struct {
+		name, config string
+		args         []string
+		err          string
+	}{
+		{name: "missing explicit config", args: []string{"-config", filepath.Join(xdg, "missing.toml")}, err: "could not read config file"},
+		{name: "unknown key", config: `colour = "red"`, err: "unknown config keys"},
+		{name: "invalid", config: `check_postpone = -1`, err: "check_postpone"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			writeFile(t, filepath.Join(xdg, "positive-hydration", "config.toml"), tt.config)
+			if _, _, err := loadConfig(tt.args); err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("expected an error containing %q, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestDefaultDB(t *testing.T) {
+	home, xdg := configEnv(t)
+	current := filepath.Join(xdg, "positive_hydration.json")
+	legacy := filepath.Join(home, ".config", "positive_hydration.json")
+
+	if got := defaultDB(); got != current {
+		t.Fatalf("expected a new DB in the config directory, got %s", got)
+	}
+	writeFile(t, legacy, "{}")
+	if got := defaultDB(); got != legacy {
+		t.Fatalf("expected the DB in ~/.config to be kept, got %s", got)
+	}
+	writeFile(t, current, "{}")
+	if got := defaultDB(); got != current {
+		t.Fatalf("expected the DB in the config directory to take precedence, got %s", got)
+	}
+}
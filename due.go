@@ -0,0 +1,184 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+)
+
+// Exit codes of the due command, so that cron jobs and status bars can
+// react without parsing the output. 0 means nothing has been reported,
+// 1 and 2 are used for errors.
+const (
+	exitDue     exitStatus = 3
+	exitOverdue exitStatus = 4
+)
+
+// exitStatus is returned by commands to set the exit code of the process
+// without it being reported as an error.
+type exitStatus int
+
+func (e exitStatus) Error() string {
+	return "exit status " + strconv.Itoa(int(e))
+}
+
+type dueStatus string
+
+const (
+	statusOverdue  dueStatus = "overdue"
+	statusToday    dueStatus = "today"
+	statusOpen     dueStatus = "open"
+	statusUpcoming dueStatus = "upcoming"
+)
+
+type dueEntry struct {
+	PlantID string    `json:"plant_id"`
+	Plant   string    `json:"plant"`
+	Task    string    `json:"task"`
+	Status  dueStatus `json:"status"`
+	// DueIn and DueDate are when the task can be done, and ClosesIn and
+	// ClosesDate when it needs to be done.
+	DueIn      int    `json:"due_in_days"`
+	DueDate    string `json:"due_date"`
+	ClosesIn   int    `json:"closes_in_days"`
+	ClosesDate string `json:"closes_date"`
+}
+
+// dueReport lists all watering, fertilizing, custom tasks and reminders
+// that are overdue or due within the next `within` days, sorted by when
+// they need to be done.
+func (pDB *PlantDB) dueReport(within int) []dueEntry {
+	var entries []dueEntry
+	add := func(p *Plant, task string, w window, ok bool) {
+		if !ok || w.Opens > within {
+			return
+		}
+		status := statusUpcoming
+		switch {
+		case w.Closes < 0:
+			status = statusOverdue
+		case w.Closes == 0:
+			status = statusToday
+		case w.open():
+			status = statusOpen
+		}
+		entries = append(entries, dueEntry{
+			PlantID:    p.ID,
+			Plant:      p.Name,
+			Task:       task,
+			Status:     status,
+			DueIn:      w.Opens,
+			DueDate:    timeNow().AddDate(0, 0, w.Opens).Format("2006-01-02"),
+			ClosesIn:   w.Closes,
+			ClosesDate: timeNow().AddDate(0, 0, w.Closes).Format("2006-01-02"),
+		})
+	}
+
+	for _, p := range pDB.Plants {
+		if p.archived() {
+			continue
+		}
+		for _, task := range []struct {
+			name string
+			kind eventKind
+		}{{"watering", eventWatered}, {"fertilizing", eventFertilized}} {
+			w, ok := p.scheduled(pDB.sched, task.kind)
+			add(p, task.name, w, ok)
+		}
+		for _, t := range customTasks {
+			w, ok := p.scheduled(pDB.sched, t.kind)
+			add(p, string(t.kind), w, ok)
+		}
+		for _, i := range p.openReminders() {
+			days := daysFromToday(p.Reminders[i].Due)
+			add(p, "reminder: "+p.Reminders[i].Text, window{Opens: days, Closes: days}, true)
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].ClosesIn != entries[j].ClosesIn {
+			return entries[i].ClosesIn < entries[j].ClosesIn
+		}
+		return entries[i].DueIn < entries[j].DueIn
+	})
+	return entries
+}
+
+func duePlants(pDB *PlantDB, args []string) error {
+	fs := flag.NewFlagSet("due", flag.ContinueOnError)
+	within := fs.Int("within", 0, "also report tasks due within the given number of days")
+	format := fs.String("format", "text", "output format: text, json or tsv")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var write func(io.Writer, []dueEntry) error
+	switch *format {
+	case "text":
+		write = writeDueText
+	case "json":
+		write = writeDueJSON
+	case "tsv":
+		write = writeDueTSV
+	default:
+		return fmt.Errorf("unknown format %q", *format)
+	}
+
+	entries := pDB.dueReport(*within)
+	if err := write(os.Stdout, entries); err != nil {
+		return err
+	}
+
+	return dueExitStatus(entries)
+}
+
+func dueExitStatus(entries []dueEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	for _, e := range entries {
+		if e.Status == statusOverdue {
+			return exitOverdue
+		}
+	}
+	return exitDue
+}
+
+func writeDueText(w io.Writer, entries []dueEntry) error {
+	if len(entries) == 0 {
+		_, err := fmt.Fprintln(w, "Nothing to do, your plants are happy!")
+		return err
+	}
+	for _, e := range entries {
+		if _, err := fmt.Fprintf(w, "%-9s %s: %s %s\n",
+			e.Status, e.Plant, e.Task, window{Opens: e.DueIn, Closes: e.ClosesIn},
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeDueJSON(w io.Writer, entries []dueEntry) error {
+	if entries == nil {
+		entries = []dueEntry{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(entries)
+}
+
+func writeDueTSV(w io.Writer, entries []dueEntry) error {
+	for _, e := range entries {
+		if _, err := fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%s\t%s\t%d\n",
+			e.Status, e.Plant, e.Task, e.DueDate, e.DueIn, e.PlantID, e.ClosesDate, e.ClosesIn,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// dueDB returns a DB with a plant for each due status as of 2024-06-15.
+func dueDB(t *testing.T, location string) *PlantDB {
+	t.Helper()
+	sched, err := newScheduler(defaultConfig().Seasons)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pDB, err := openDB(location, sched)
+	if err != nil {
+		t.Fatal(err)
+	}
+	day := func(d int) time.Time {
+		return time.Date(2024, 6, d, 12, 0, 0, 0, time.Local)
+	}
+	plant := func(id, name, intervals string, watered int) *Plant {
+		i, err := parseSeasonalIntervals(intervals)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &Plant{ID: id, Name: name, WateringIntervals: i, History: []CareEvent{{Kind: eventWatered, Time: day(watered)}}}
+	}
+	archived := plant("000000gone", "Gone", "7", 1)
+	archivedAt := day(2)
+	archived.ArchivedAt = &archivedAt
+	bob := plant("00000bob", "Bob", "7", 8)
+	bob.Reminders = []Reminder{{Text: "mist", Due: day(17)}}
+	pDB.Plants = []*Plant{
+		plant("0000cleo", "Cleo", "5-9", 12),
+		plant("0000dora", "Dora", "3-8", 11),
+		bob,
+		plant("0000fred", "Fred", "7", 1),
+		archived,
+	}
+	return pDB
+}
+
+func TestDueReport(t *testing.T) {
+	t.Cleanup(func() { asOf = time.Time{} })
+	asOf = time.Date(2024, 6, 15, 0, 0, 0, 0, time.Local)
+	pDB := dueDB(t, filepath.Join(t.TempDir(), "plants.json"))
+
+	type due struct {
+		plant, task string
+		status      dueStatus
+	}
+	for _, tt := range []struct {
+		within int
+		want   []due
+		status error
+	}{
+		{
+			within: 0,
+			want: []due{
+				{"Fred", "watering", statusOverdue},
+				{"Bob", "watering", statusToday},
+				{"Dora", "watering", statusOpen},
+			},
+		},
+		{
+			within: 3,
+			want: []due{
+				{"Fred", "watering", statusOverdue},
+				{"Bob", "watering", statusToday},
+				{"Bob", "reminder: mist", statusUpcoming},
+				{"Dora", "watering", statusOpen},
+				{"Cleo", "watering", statusUpcoming},
+			},
+		},
+	} {
+		var got []due
+		for _, e := range pDB.dueReport(tt.within) {
+			got = append(got, due{e.Plant, e.Task, e.Status})
+		}
+		if len(got) != len(tt.want) {
+			t.Fatalf("within %d: expected=%v, got=%v", tt.within, tt.want, got)
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Fatalf("within %d: expected=%v, got=%v", tt.within, tt.want, got)
+			}
+		}
+	}
+}
+
+func TestDueExitStatus(t *testing.T) {
+	for _, tt := range []struct {
+		statuses []dueStatus
+		want     error
+	}{
+		{statuses: nil, want: nil},
+		{statuses: []dueStatus{statusUpcoming, statusOpen, statusToday}, want: exitDue},
+		{statuses: []dueStatus{statusToday, statusOverdue}, want: exitOverdue},
+	} {
+		var entries []dueEntry
+		for _, s := range tt.statuses {
+			entries = append(entries, dueEntry{Status: s})
+		}
+		if got := dueExitStatus(entries); got != tt.want {
+			t.Fatalf("%v: expected=%v, got=%v", tt.statuses, tt.want, got)
+		}
+	}
+}
+
+func TestDueCommandExitCodes(t *testing.T) {
+	t.Cleanup(func() { asOf = time.Time{} })
+	asOf = time.Date(2024, 6, 15, 0, 0, 0, 0, time.Local)
+
+	for _, tt := range []struct {
+		plants []string
+		want   int
+	}{
+		{plants: []string{"Cleo", "Bob", "Fred"}, want: int(exitOverdue)},
+		{plants: []string{"Cleo", "Dora"}, want: int(exitDue)},
+		{plants: []string{"Cleo", "Gone"}, want: 0},
+	} {
+		location := filepath.Join(t.TempDir(), "plants.json")
+		pDB := dueDB(t, location)
+		var plants []*Plant
+		for _, p := range pDB.Plants {
+			for _, name := range tt.plants {
+				if p.Name == name {
+					plants = append(plants, p)
+				}
+			}
+		}
+		pDB.Plants = plants
+		if err := pDB.Save(); err != nil {
+			t.Fatal(err)
+		}
+		if code := runCommand(pDB, []string{"due", "-format", "tsv"}); code != tt.want {
+			t.Fatalf("%v: expected exit code %d, got %d", tt.plants, tt.want, code)
+		}
+	}
+}
+
+func TestDueOutput(t *testing.T) {
+	entries := []dueEntry{{
+		PlantID: "0000fred", Plant: "Fred", Task: "watering", Status: statusOverdue,
+		DueIn: -2, DueDate: "2024-06-13", ClosesIn: -1, ClosesDate: "2024-06-14",
+	}}
+
+	var tsv bytes.Buffer
+	if err := writeDueTSV(&tsv, entries); err != nil {
+		t.Fatal(err)
+	}
+	if want := "overdue\tFred\twatering\t2024-06-13\t-2\t0000fred\t2024-06-14\t-1\n"; tsv.String() != want {
+		t.Fatalf("wrong TSV. expected=%q, got=%q", want, tsv.String())
+	}
+
+	var out bytes.Buffer
+	if err := writeDueJSON(&out, nil); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "[]\n" {
+		t.Fatalf("expected an empty list, got %q", out.String())
+	}
+	out.Reset()
+	if err := writeDueJSON(&out, entries); err != nil {
+		t.Fatal(err)
+	}
+	var got []map[string]any
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"plant_id": "0000fred", "plant": "Fred", "task": "watering", "status": "overdue",
+		"due_in_days": -2.0, "due_date": "2024-06-13", "closes_in_days": -1.0, "closes_date": "2024-06-14",
+	}
+	if len(got) != 1 || len(got[0]) != len(want) {
+		t.Fatalf("wrong JSON: %s", out.String())
+	}
+	for k, v := range want {
+		if got[0][k] != v {
+			t.Fatalf("wrong %s. expected=%v, got=%v", k, v, got[0][k])
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type eventKind string
+
+const (
+	eventWatered    eventKind = "watered"
+	eventFertilized eventKind = "fertilized"
+	eventRepotted   eventKind = "repotted"
+	// eventChecked is a check of the soil that showed the plant didn't
+	// need water yet.
+	eventChecked eventKind = "checked"
+	// eventSnoozed and eventSkipped defer the task in Task, see
+	// Plant.deferrals.
+	eventSnoozed eventKind = "snoozed"
+	eventSkipped eventKind = "skipped"
+)
+
+// eventKinds are the builtin kinds. In the calendar, later kinds take
+// precedence, so deferrals and checks come first.
+var eventKinds = []eventKind{eventSnoozed, eventSkipped, eventChecked, eventWatered, eventFertilized, eventRepotted}
+
+// CareEvent is a single thing that has been done to a plant.
+type CareEvent struct {
+	Time time.Time `json:"time"`
+	Kind eventKind `json:"kind"`
+	// Amount is free text, e.g. "500ml" or "2 spoons".
+	Amount  string `json:"amount,omitempty"`
+	Product string `json:"product,omitempty"`
+	// Actor is who did it.
+	Actor string `json:"actor,omitempty"`
+	Note  string `json:"note,omitempty"`
+
+	// Fertilizer, Dilution and NPK are only set on fertilizations.
+	Fertilizer FertilizerType `json:"fertilizer,omitempty"`
+	// Dilution is free text, e.g. "1:100".
+	Dilution string `json:"dilution,omitempty"`
+	// NPK is the nitrogen-phosphorus-potassium ratio, e.g. "7-3-6".
+	NPK string `json:"npk,omitempty"`
+
+	// FromPotSize, PotSize, PotMaterial and SoilMix are only set on
+	// repottings. The sizes are in cm.
+	FromPotSize int    `json:"from_pot_size,omitempty"`
+	PotSize     int    `json:"pot_size,omitempty"`
+	PotMaterial string `json:"pot_material,omitempty"`
+	SoilMix     string `json:"soil_mix,omitempty"`
+
+	// Task is the task that has been snoozed or skipped, Days how many
+	// days it has been snoozed by.
+	Task eventKind `json:"task,omitempty"`
+	Days int       `json:"days,omitempty"`
+}
+
+// details returns everything but the time and kind of e in a single line.
+func (e CareEvent) details() string {
+	var parts []string
+	if e.Task != "" {
+		task := taskName(e.Task)
+		switch e.Days {
+		case 0:
+		case 1:
+			task += " by 1 day"
+		default:
+			task += " by " + strconv.Itoa(e.Days) + " days"
+		}
+		parts = append(parts, task)
+	}
+	if e.PotSize != 0 {
+		parts = append(parts, formatPotSizes(e.FromPotSize, e.PotSize))
+	}
+	for _, s := range []string{string(e.Fertilizer), e.Product, e.Amount, e.Dilution, e.PotMaterial, e.SoilMix} {
+		if s != "" {
+			parts = append(parts, s)
+		}
+	}
+	if e.NPK != "" {
+		parts = append(parts, "NPK "+e.NPK)
+	}
+	if e.Actor != "" {
+		parts = append(parts, "by "+e.Actor)
+	}
+	if e.Note != "" {
+		parts = append(parts, "\""+e.Note+"\"")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// fertilizer returns what has been used for a fertilization, the product
+// if it is known, otherwise the type.
+func (e CareEvent) fertilizer() string {
+	if e.Product != "" {
+		return e.Product
+	}
+	return string(e.Fertilizer)
+}
+
+// sameDay returns true if a and b are on the same local calendar day.
+func sameDay(a, b time.Time) bool {
+	return daysBetween(a, b) == 0
+}
+
+// newEvent returns an event of the given kind, done by the configured
+// actor.
+func (pDB *PlantDB) newEvent(kind eventKind, t time.Time) CareEvent {
+	return CareEvent{Time: t, Kind: kind, Actor: pDB.actor}
+}
+
+// times returns the times of all events of the given kind, oldest first.
+func (p Plant) times(kind eventKind) []time.Time {
+	var times []time.Time
+	for _, e := range p.History {
+		if e.Kind == kind {
+			times = append(times, e.Time)
+		}
+	}
+	sort.Slice(times, func(i, j int) bool {
+		return times[i].Before(times[j])
+	})
+	return times
+}
+
+// lastEvent returns the most recent event of the given kind.
+func (p Plant) lastEvent(kind eventKind) (CareEvent, bool) {
+	for i := len(p.History) - 1; i >= 0; i-- {
+		if p.History[i].Kind == kind {
+			return p.History[i], true
+		}
+	}
+	return CareEvent{}, false
+}
+
+// lastEvents returns up to n of the most recent events, most recent
+// first.
+func (p Plant) lastEvents(n int) []CareEvent {
+	var events []CareEvent
+	for i := len(p.History) - 1; i >= 0 && len(events) < n; i-- {
+		events = append(events, p.History[i])
+	}
+	return events
+}
+
+// eventOn returns the index of the event of the given kind on the same
+// day as t, or -1 if there is none.
+func (p Plant) eventOn(kind eventKind, t time.Time) int {
+	for i, e := range p.History {
+		if e.Kind == kind && sameDay(e.Time, t) {
+			return i
+		}
+	}
+	return -1
+}
+
+// removeEvent removes the event at index i. Removing the latest
+// repotting puts the plant back into its previous pot.
+func (p *Plant) removeEvent(i int) {
+	e := p.History[i]
+	if e.Kind == eventRepotted && e.FromPotSize != 0 && p.repotAfter(e.Time) < 0 {
+		p.PotSize = e.FromPotSize
+	}
+	p.History = append(p.History[:i], p.History[i+1:]...)
+}
+
+// toggleEvent adds e, or removes the event of the same kind on the same
+// day if there is one.
+func (p *Plant) toggleEvent(e CareEvent) {
+	if i := p.eventOn(e.Kind, e.Time); i >= 0 {
+		p.removeEvent(i)
+		return
+	}
+	p.appendEvent(e)
+}
+
+func sortEvents(events []CareEvent) {
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Time.Before(events[j].Time)
+	})
+}
@@ -0,0 +1,346 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// forecastTask is a task that is expected to be done on Day, in days from
+// today.
+type forecastTask struct {
+	Day   int
+	Plant *Plant
+	Kind  eventKind
+}
+
+// forecastKinds returns the kinds of the tasks that are forecast.
+func forecastKinds() []eventKind {
+	kinds := []eventKind{eventWatered, eventFertilized, eventRepotted}
+	for _, t := range customTasks {
+		kinds = append(kinds, t.kind)
+	}
+	return kinds
+}
+
+// forecast simulates the tasks of all active plants over the given number
+// of days, sorted by day. Every task is assumed to be done on the day its
+// window opens, or today if it is overdue, and to be due again after the
+// interval that applies on that day.
+func (pDB *PlantDB) forecast(days int) []forecastTask {
+	var tasks []forecastTask
+	for _, p := range pDB.Plants {
+		if p.archived() {
+			continue
+		}
+		for _, kind := range forecastKinds() {
+			tasks = append(tasks, p.simulate(pDB.sched, kind, days)...)
+		}
+	}
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return tasks[i].Day < tasks[j].Day
+	})
+	return tasks
+}
+
+// simulate returns the occurrences of the task within the given number
+// of days.
+func (p *Plant) simulate(sched *Scheduler, kind eventKind, days int) []forecastTask {
+	var tasks []forecastTask
+	w, ok := p.expected(sched, kind)
+	for ok {
+		day := w.Opens
+		if day < 0 {
+			day = 0
+		}
+		if day >= days {
+			break
+		}
+		tasks = append(tasks, forecastTask{Day: day, Plant: p, Kind: kind})
+
+		w, ok = p.expectedAfter(sched, kind, timeNow().AddDate(0, 0, day))
+		// tasks that could be done again on the same day are counted once
+		// per day.
+		if w.Opens <= day {
+			w.Opens = day + 1
+		}
+	}
+	return tasks
+}
+
+// expected returns the window of the next occurrence of the task. Repots
+// aren't scheduled, they are expected after the average interval between
+// the past ones.
+func (p Plant) expected(sched *Scheduler, kind eventKind) (window, bool) {
+	if kind != eventRepotted {
+		return p.scheduled(sched, kind)
+	}
+	interval, ok := p.repotInterval()
+	if !ok {
+		return window{}, false
+	}
+	return windowAfter(last(p.times(eventRepotted)), interval), true
+}
+
+// expectedAfter returns the window of the task if it is done on the day
+// of done, with the intervals that apply then.
+func (p Plant) expectedAfter(sched *Scheduler, kind eventKind, done time.Time) (window, bool) {
+	if kind == eventRepotted {
+		interval, ok := p.repotInterval()
+		return windowAfter(done, interval), ok
+	}
+	w, ok := sched.scheduledAt(done, p.intervals(kind), done)
+	if !ok || kind != eventWatered {
+		return w, ok
+	}
+	if learned, adaptive := p.adaptiveInterval(sched, done); adaptive {
+		w = windowAfter(done, learned)
+	}
+	return sched.aligned(w), true
+}
+
+// repotInterval returns the average interval between the past repots,
+// it's not ok if there have been less than two.
+func (p Plant) repotInterval() (dayRange, bool) {
+	avg := average(p.times(eventRepotted), 0)
+	if math.IsNaN(avg) {
+		return dayRange{}, false
+	}
+	days := int(math.Round(avg))
+	return dayRange{Min: days, Max: days}, true
+}
+
+// workloadPeriod is the number of tasks per kind expected in the days
+// from Start on.
+type workloadPeriod struct {
+	Start  int
+	Counts map[eventKind]int
+	Total  int
+}
+
+// workload sums up the tasks in periods of the given length in days, e.g.
+// 7 for weeks, covering the given number of days.
+func workload(tasks []forecastTask, days, length int) []workloadPeriod {
+	var periods []workloadPeriod
+	for start := 0; start < days; start += length {
+		periods = append(periods, workloadPeriod{Start: start, Counts: map[eventKind]int{}})
+	}
+	for _, t := range tasks {
+		if t.Day >= days {
+			continue
+		}
+		wp := &periods[t.Day/length]
+		wp.Counts[t.Kind]++
+		wp.Total++
+	}
+	return periods
+}
+
+// forecastBarWidth is the maximum width of a bar of the workload chart.
+const forecastBarWidth = 30
+
+// renderWorkload renders a bar per period, with blocks in the colour of
+// the kinds of the tasks. Bars are scaled down to forecastBarWidth.
+func renderWorkload(periods []workloadPeriod, length int) string {
+	layout := "Mon Jan 02"
+	if length != 1 {
+		layout = "Jan 02"
+	}
+	max := 0
+	for _, wp := range periods {
+		if wp.Total > max {
+			max = wp.Total
+		}
+	}
+	blocks := func(n int) int {
+		if max <= forecastBarWidth {
+			return n
+		}
+		return (n*forecastBarWidth + max - 1) / max
+	}
+	var lines []string
+	for _, wp := range periods {
+		var bar strings.Builder
+		var counts []string
+		for _, kind := range forecastKinds() {
+			n := wp.Counts[kind]
+			if n == 0 {
+				continue
+			}
+			bar.WriteString(lipgloss.NewStyle().Foreground(eventColor(kind)).Render(strings.Repeat("█", blocks(n))))
+			counts = append(counts, strconv.Itoa(n)+" "+taskName(kind))
+		}
+		line := fmt.Sprintf("%-10s %3d %s", timeNow().AddDate(0, 0, wp.Start).Format(layout), wp.Total, bar.String())
+		if len(counts) > 0 {
+			line += " " + blurredStyle.Render(strings.Join(counts, ", "))
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// String describes a single task of the forecast.
+func (t forecastTask) String() string {
+	return fmt.Sprintf("%s  %s: %s",
+		timeNow().AddDate(0, 0, t.Day).Format("Mon Jan 02"), t.Plant.Name, taskName(t.Kind))
+}
+
+const (
+	// forecastWidth is the width of the forecast in the UI.
+	forecastWidth = 94
+	// forecastWeeks is how far the forecast in the UI reaches.
+	forecastWeeks = 6
+	// forecastListDays is for how many days the UI lists the tasks.
+	forecastListDays = 7
+	// forecastListLines is how many tasks the UI lists at most.
+	forecastListLines = 12
+)
+
+// renderForecast shows the weekly workload and the tasks of the coming
+// days in the UI.
+func (pDB *PlantDB) renderForecast() string {
+	days := forecastWeeks * 7
+	tasks := pDB.forecast(days)
+
+	var list []string
+	for i, t := range tasks {
+		if t.Day >= forecastListDays {
+			break
+		}
+		if i == forecastListLines {
+			list = append(list, blurredStyle.Render(fmt.Sprintf("... and %d more", countBefore(tasks, forecastListDays)-i)))
+			break
+		}
+		list = append(list, t.String())
+	}
+	if len(list) == 0 {
+		list = append(list, blurredStyle.Render("Nothing to do this week."))
+	}
+
+	return lipgloss.NewStyle().Width(forecastWidth).Render(lipgloss.JoinVertical(lipgloss.Left,
+		titleStyle.Render(fmt.Sprintf("Workload, next %d weeks", forecastWeeks)),
+		"",
+		renderWorkload(workload(tasks, days, 7), 7),
+		"",
+		titleStyle.Render(fmt.Sprintf("Next %d days", forecastListDays)),
+		"",
+		strings.Join(list, "\n"),
+	))
+}
+
+// countBefore returns the number of tasks before the given day.
+func countBefore(tasks []forecastTask, day int) int {
+	n := 0
+	for _, t := range tasks {
+		if t.Day < day {
+			n++
+		}
+	}
+	return n
+}
+
+type forecastEntry struct {
+	Date    string `json:"date"`
+	PlantID string `json:"plant_id"`
+	Plant   string `json:"plant"`
+	Task    string `json:"task"`
+}
+
+type workloadEntry struct {
+	Start  string         `json:"start"`
+	Total  int            `json:"total"`
+	Counts map[string]int `json:"counts"`
+}
+
+func forecastPlants(pDB *PlantDB, args []string) error {
+	fs := flag.NewFlagSet("forecast", flag.ContinueOnError)
+	weeks := fs.Int("weeks", 4, "how many weeks to forecast")
+	by := fs.String("by", "week", "sum up the workload by day or week")
+	list := fs.Bool("list", false, "also list every task")
+	format := fs.String("format", "text", "output format: text or json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *weeks < 1 {
+		return fmt.Errorf("weeks need to be a positive number")
+	}
+	length := 7
+	switch *by {
+	case "week":
+	case "day":
+		length = 1
+	default:
+		return fmt.Errorf("unknown period %q, expected day or week", *by)
+	}
+
+	days := *weeks * 7
+	tasks := pDB.forecast(days)
+	periods := workload(tasks, days, length)
+	switch *format {
+	case "text":
+		return writeForecastText(os.Stdout, periods, length, tasks, *list)
+	case "json":
+		return writeForecastJSON(os.Stdout, periods, tasks)
+	default:
+		return fmt.Errorf("unknown format %q", *format)
+	}
+}
+
+func writeForecastText(w io.Writer, periods []workloadPeriod, length int, tasks []forecastTask, list bool) error {
+	if _, err := fmt.Fprintln(w, renderWorkload(periods, length)); err != nil {
+		return err
+	}
+	if !list {
+		return nil
+	}
+	if _, err := fmt.Fprintln(w); err != nil {
+		return err
+	}
+	for _, t := range tasks {
+		if _, err := fmt.Fprintln(w, t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeForecastJSON(w io.Writer, periods []workloadPeriod, tasks []forecastTask) error {
+	out := struct {
+		Workload []workloadEntry `json:"workload"`
+		Tasks    []forecastEntry `json:"tasks"`
+	}{
+		Workload: []workloadEntry{},
+		Tasks:    []forecastEntry{},
+	}
+	for _, wp := range periods {
+		counts := map[string]int{}
+		for kind, n := range wp.Counts {
+			counts[taskName(kind)] = n
+		}
+		out.Workload = append(out.Workload, workloadEntry{
+			Start:  timeNow().AddDate(0, 0, wp.Start).Format("2006-01-02"),
+			Total:  wp.Total,
+			Counts: counts,
+		})
+	}
+	for _, t := range tasks {
+		out.Tasks = append(out.Tasks, forecastEntry{
+			Date:    timeNow().AddDate(0, 0, t.Day).Format("2006-01-02"),
+			PlantID: t.Plant.ID,
+			Plant:   t.Plant.Name,
+			Task:    taskName(t.Kind),
+		})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// forecastDays returns the days of the tasks.
+func forecastDays(tasks []forecastTask) []int {
+	days := []int{}
+	for _, t := range tasks {
+		days = append(days, t.Day)
+	}
+	return days
+}
+
+func TestForecast(t *testing.T) {
+	t.Cleanup(func() { asOf = time.Time{} })
+	sched, err := newScheduler(defaultConfig().Seasons)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the default winter starts on Nov 12.
+	asOf = time.Date(2024, 11, 1, 0, 0, 0, 0, time.Local)
+	day := func(month time.Month, d int) time.Time {
+		return time.Date(2024, month, d, 12, 0, 0, 0, time.Local)
+	}
+	intervals := func(s string) SeasonalIntervals {
+		i, err := parseSeasonalIntervals(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return i
+	}
+
+	for _, tt := range []struct {
+		name string
+		p    Plant
+		kind eventKind
+		days int
+		want []int
+	}{
+		{
+			name: "season change",
+			p: Plant{
+				WateringIntervals: intervals("7/14"),
+				History:           []CareEvent{{Kind: eventWatered, Time: day(time.November, 1)}},
+			},
+			kind: eventWatered, days: 35,
+			// Nov 15 is in winter already.
+			want: []int{7, 14, 28},
+		},
+		{
+			name: "overdue",
+			p: Plant{
+				WateringIntervals: intervals("7"),
+				History:           []CareEvent{{Kind: eventWatered, Time: day(time.October, 1)}},
+			},
+			kind: eventWatered, days: 14,
+			want: []int{0, 7},
+		},
+		{
+			name: "once per day",
+			p: Plant{
+				WateringIntervals: intervals("0-2"),
+				History:           []CareEvent{{Kind: eventWatered, Time: day(time.November, 1)}},
+			},
+			kind: eventWatered, days: 5,
+			want: []int{0, 1, 2, 3, 4},
+		},
+		{
+			name: "not scheduled",
+			p:    Plant{History: []CareEvent{{Kind: eventWatered, Time: day(time.November, 1)}}},
+			kind: eventWatered, days: 35,
+			want: []int{},
+		},
+		{
+			name: "repots every 152 days on average",
+			p: Plant{History: []CareEvent{
+				{Kind: eventRepotted, Time: day(time.January, 1)},
+				{Kind: eventRepotted, Time: day(time.June, 1)},
+			}},
+			// due on Oct 31, i.e. overdue.
+			kind: eventRepotted, days: 365,
+			want: []int{0, 152, 304},
+		},
+		{
+			name: "single repot",
+			p:    Plant{History: []CareEvent{{Kind: eventRepotted, Time: day(time.June, 1)}}},
+			kind: eventRepotted, days: 365,
+			want: []int{},
+		},
+	} {
+		if got := forecastDays(tt.p.simulate(sched, tt.kind, tt.days)); !reflect.DeepEqual(got, tt.want) {
+			t.Fatalf("%s: expected=%v, got=%v", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestWorkload(t *testing.T) {
+	t.Cleanup(func() { asOf = time.Time{} })
+	sched, err := newScheduler(defaultConfig().Seasons)
+	if err != nil {
+		t.Fatal(err)
+	}
+	asOf = time.Date(2024, 11, 1, 0, 0, 0, 0, time.Local)
+	watering, err := parseSeasonalIntervals("7/14")
+	if err != nil {
+		t.Fatal(err)
+	}
+	watered := []CareEvent{{Kind: eventWatered, Time: time.Date(2024, 11, 1, 12, 0, 0, 0, time.Local)}}
+	archivedAt := time.Date(2024, 10, 1, 0, 0, 0, 0, time.Local)
+	pDB := &PlantDB{sched: sched, Plants: []*Plant{
+		{Name: "Fred", WateringIntervals: watering, History: watered},
+		{Name: "Bob", WateringIntervals: watering, History: watered},
+		{Name: "Gone", WateringIntervals: watering, History: watered, ArchivedAt: &archivedAt},
+	}}
+
+	periods := workload(pDB.forecast(35), 35, 7)
+	var totals []int
+	for _, wp := range periods {
+		totals = append(totals, wp.Total)
+		if wp.Counts[eventWatered] != wp.Total {
+			t.Fatalf("expected only waterings in %+v", wp)
+		}
+	}
+	if want := []int{0, 2, 2, 0, 2}; !reflect.DeepEqual(totals, want) {
+		t.Fatalf("expected=%v, got=%v", want, totals)
+	}
+}
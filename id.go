@@ -0,0 +1,34 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// newPlantID returns a random ID that isn't taken yet. IDs are short so
+// that they can be typed on the command line.
+func newPlantID(taken map[string]bool) string {
+	for {
+		b := make([]byte, 4)
+		if _, err := rand.Read(b); err != nil {
+			panic("could not generate plant ID: " + err.Error())
+		}
+		if id := hex.EncodeToString(b); !taken[id] {
+			return id
+		}
+	}
+}
+
+func (pDB *PlantDB) ids() map[string]bool {
+	ids := make(map[string]bool, len(pDB.Plants))
+	for _, p := range pDB.Plants {
+		ids[p.ID] = true
+	}
+	return ids
+}
+
+// add adds p to the DB with a new ID.
+func (pDB *PlantDB) add(p *Plant) {
+	p.ID = newPlantID(pDB.ids())
+	pDB.Plants = append(pDB.Plants, p)
+}
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package main
+
+// lockFile is a no-op on platforms without flock.
+func lockFile(name string, exclusive bool) (func(), error) {
+	return func() {}, nil
+}
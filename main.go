@@ -1,15 +1,18 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
 	"math"
 	"os"
-	"path"
+	"os/signal"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/charmbracelet/bubbles/key"
@@ -48,22 +51,38 @@ type ShowPlants struct {
 	*PlantDB
 	showPlant *Plant
 	list      list.Model
+	keys      keyMap
+	// trash shows the archived plants instead of the active ones.
+	trash bool
+	// forecast shows the workload forecast instead of the selected plant.
+	forecast bool
+	history  *history
 
 	prompt tea.Model
+	// err is the last error that happened while saving.
+	err error
+	// status is shown until the next key press.
+	status string
 }
 
-func newShowPlants(pDB *PlantDB) *ShowPlants {
+func newShowPlants(pDB *PlantDB, keys keyMap, h *history) *ShowPlants {
 	delegate := list.NewDefaultDelegate()
 	delegate.SetHeight(3)
+	delegate.Styles.SelectedTitle = delegate.Styles.SelectedTitle.Copy().
+		Foreground(selectedColor).BorderForeground(selectedColor)
+	delegate.Styles.SelectedDesc = delegate.Styles.SelectedDesc.Copy().
+		Foreground(selectedColor).BorderForeground(selectedColor)
 	l := list.New(pDB.Items(), delegate, 80, 31)
-	// overwrite nextPage keys as "f" is used to mark as fertilized.
-	var keys []string
-	for _, k := range l.KeyMap.NextPage.Keys() {
-		if k != "f" {
-			keys = append(keys, k)
-		}
+	// remove our keys from the list's navigation, e.g. "f" is used to
+	// mark as fertilized but is also a nextPage key.
+	for _, b := range []*key.Binding{
+		&l.KeyMap.CursorUp, &l.KeyMap.CursorDown,
+		&l.KeyMap.NextPage, &l.KeyMap.PrevPage,
+		&l.KeyMap.GoToStart, &l.KeyMap.GoToEnd,
+	} {
+		unbind(b, keys.all())
 	}
-	l.KeyMap.NextPage.SetKeys(keys...)
+	l.KeyMap.Quit = keys.Quit
 
 	l.Title = "Your Glorious Plants"
 	l.SetShowStatusBar(false)
@@ -72,51 +91,14 @@ func newShowPlants(pDB *PlantDB) *ShowPlants {
 	l.Styles.Title = titleStyle
 	l.Styles.HelpStyle = list.DefaultStyles().HelpStyle.PaddingLeft(4)
 	l.Styles.PaginationStyle = paginationStyle
-	l.AdditionalShortHelpKeys = func() []key.Binding {
-		return []key.Binding{
-			key.NewBinding(key.WithKeys("a"), key.WithHelp("a", "add")),
-			key.NewBinding(key.WithKeys("w"), key.WithHelp("w", "water")),
-			key.NewBinding(key.WithKeys("f"), key.WithHelp("f", "fertilized")),
-			key.NewBinding(key.WithKeys("p"), key.WithHelp("p", "repotted")),
-			key.NewBinding(key.WithKeys("e"), key.WithHelp("e", "edit")),
-		}
-	}
-	l.AdditionalFullHelpKeys = func() []key.Binding {
-		return []key.Binding{
-			key.NewBinding(
-				key.WithKeys("a"),
-				key.WithHelp("a", "add plant"),
-			),
-			key.NewBinding(
-				key.WithKeys("c"),
-				key.WithHelp("c", "copy plant"),
-			),
-			key.NewBinding(
-				key.WithKeys("w"),
-				key.WithHelp("w", "mark as watered"),
-			),
-			key.NewBinding(
-				key.WithKeys("W"),
-				key.WithHelp("W", "mark as watered with specific date"),
-			),
-			key.NewBinding(
-				key.WithKeys("f"),
-				key.WithHelp("f", "mark as fertilized"),
-			),
-			key.NewBinding(
-				key.WithKeys("p"),
-				key.WithHelp("p", "mark as repotted"),
-			),
-			key.NewBinding(
-				key.WithKeys("e"),
-				key.WithHelp("e", "edit plant"),
-			),
-		}
-	}
+	l.AdditionalShortHelpKeys = keys.ShortHelp
+	l.AdditionalFullHelpKeys = keys.FullHelp
 
 	return &ShowPlants{
 		PlantDB: pDB,
 		list:    l,
+		keys:    keys,
+		history: h,
 	}
 }
 
@@ -129,15 +111,17 @@ func (sp *ShowPlants) View() string {
 		//// TODO: this would return a command, but I'm not sure what to do with it.
 		//_ = sp.list.SetItems(sp.PlantDB.Items())
 		//})
-	} else if len(sp.list.VisibleItems()) > 0 {
-		sp.showPlant = sp.list.VisibleItems()[sp.list.Index()].(*Plant)
-		right = sp.showPlant.Render(!sp.list.Help.ShowAll)
+	} else if sp.forecast && !sp.trash {
+		right = sp.renderForecast()
+	} else if p := sp.selected(); p != nil {
+		sp.showPlant = p
+		right = sp.showPlant.Render(sp.sched, !sp.list.Help.ShowAll)
 	}
 
 	if sp.prompt != nil {
 		right = lipgloss.NewStyle().
 			BorderStyle(lipgloss.NormalBorder()).
-			BorderForeground(lipgloss.Color("240")).
+			BorderForeground(borderColor).
 			Padding(0, 1, 0).
 			Height(lipgloss.Height(right)-2). // for borders, I think
 			Width(lipgloss.Width(right)-2).   // for padding
@@ -146,8 +130,20 @@ func (sp *ShowPlants) View() string {
 	}
 
 	sp.list.Help.Width = lipgloss.Width(right) - 2
+	help := sp.list.Help.View(sp.list)
+	if sp.err != nil {
+		help = lipgloss.JoinVertical(lipgloss.Center, "Could not save: "+sp.err.Error(), help)
+	}
+	if sp.status != "" {
+		help = lipgloss.JoinVertical(lipgloss.Center, sp.status, help)
+	}
+	day := sp.sched.season(timeNow())
+	if s := asOfStatus(); s != "" {
+		day += ", " + s
+	}
+	help = lipgloss.JoinVertical(lipgloss.Center, blurredStyle.Render(day), help)
 	right = lipgloss.JoinVertical(lipgloss.Center, right,
-		lipgloss.NewStyle().Height(31-lipgloss.Height(right)).Align(lipgloss.Center, lipgloss.Bottom).Render(sp.list.Help.View(sp.list)),
+		lipgloss.NewStyle().Height(31-lipgloss.Height(right)).Align(lipgloss.Center, lipgloss.Bottom).Render(help),
 	)
 
 	return lipgloss.JoinHorizontal(lipgloss.Top,
@@ -157,12 +153,27 @@ func (sp *ShowPlants) View() string {
 }
 
 func (sp *ShowPlants) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if _, ok := msg.(dbCheckMsg); ok {
+		sp.checkDB()
+		return sp, watchDB()
+	}
+
+	if _, ok := msg.(tea.KeyMsg); ok {
+		sp.status = ""
+	}
+	m, cmd := sp.update(msg)
+	if _, ok := msg.(tea.KeyMsg); ok {
+		sp.history.record(sp.PlantDB)
+		// autosave, Save is a no-op if nothing has changed.
+		sp.handleConflict(sp.PlantDB.Save())
+	}
+	return m, cmd
+}
+
+func (sp *ShowPlants) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	if len(sp.Plants) == 0 && sp.prompt == nil {
-		msg = tea.KeyMsg{
-			Alt:   false,
-			Type:  tea.KeyRunes,
-			Runes: []rune{'a'},
-		}
+		sp.openAddPrompt()
+		return sp, nil
 	}
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
@@ -170,55 +181,155 @@ func (sp *ShowPlants) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return sp, nil
 
 	case tea.KeyMsg:
-		switch keypress := msg.String(); keypress {
-		case "ctrl+c":
+		switch {
+		case msg.String() == "ctrl+c":
 			return sp, tea.Quit
 
-		case "c", "w", "f", "p", "W", "e":
+		case key.Matches(msg, sp.keys.Forecast):
+			if sp.list.SettingFilter() || sp.prompt != nil {
+				break
+			}
+			sp.forecast = !sp.forecast
+			return sp, nil
+
+		case key.Matches(msg, sp.keys.AsOf):
+			if sp.list.SettingFilter() || sp.prompt != nil {
+				break
+			}
+			sp.prompt = newAsOfPrompt(sp.refreshItems)
+			return sp, nil
+
+		case key.Matches(msg, sp.keys.Trash):
+			if sp.list.SettingFilter() || sp.prompt != nil {
+				break
+			}
+			sp.toggleTrash()
+			return sp, nil
+
+		case sp.trash && key.Matches(msg, sp.keys.Restore, sp.keys.Delete):
 			if sp.list.SettingFilter() || sp.prompt != nil {
 				break
 			}
-			if len(sp.list.VisibleItems()) > 0 {
-				p := sp.list.VisibleItems()[sp.list.Index()].(*Plant)
-				switch keypress {
-				case "c":
+			if p := sp.selected(); p != nil {
+				switch {
+				case key.Matches(msg, sp.keys.Restore):
+					sp.PlantDB.restore(p)
+					sp.refreshItems()
+				case key.Matches(msg, sp.keys.Delete):
+					sp.prompt = newDeletePrompt(sp.PlantDB, p, sp.refreshItems)
+				}
+			}
+			return sp, nil
+
+		case !sp.trash && key.Matches(msg, sp.keys.Copy, sp.keys.Water, sp.keys.WaterOn, sp.keys.Check,
+			sp.keys.Fertilize, sp.keys.Repot, sp.keys.Edit, sp.keys.Archive, sp.keys.Delete):
+			if sp.list.SettingFilter() || sp.prompt != nil {
+				break
+			}
+			if p := sp.selected(); p != nil {
+				switch {
+				case key.Matches(msg, sp.keys.Copy):
 					copied := p.Clone()
 					sp.prompt = copied.Prompt("Copy Plant", func(p *Plant) {
-						sp.PlantDB.Plants = append(sp.PlantDB.Plants, p)
-						// TODO: this would return a command, but I'm not sure what to do with it.
-						_ = sp.list.SetItems(sp.PlantDB.Items())
+						sp.PlantDB.add(p)
+						sp.refreshItems()
 					})
 					return sp, nil
-				case "w":
-					p.WateredAt = toggleEvent(p.WateredAt, time.Now())
-				case "W":
-					sp.prompt = newWateringPrompt(p)
+				case key.Matches(msg, sp.keys.Water):
+					p.toggleEvent(sp.PlantDB.newEvent(eventWatered, timeNow()))
+				case key.Matches(msg, sp.keys.WaterOn):
+					sp.prompt = newWateringPrompt(sp.PlantDB, p)
 					return sp, nil
-				case "f":
-					sp.prompt = newFertilizerPrompt(p)
+				case key.Matches(msg, sp.keys.Check):
+					p.toggleEvent(sp.PlantDB.newEvent(eventChecked, timeNow()))
+				case key.Matches(msg, sp.keys.Fertilize):
+					sp.prompt = newFertilizerPrompt(sp.PlantDB, p)
 					return sp, nil
-				case "p":
-					sp.prompt = newRepottingPrompt(p)
+				case key.Matches(msg, sp.keys.Repot):
+					sp.prompt = newRepottingPrompt(sp.PlantDB, p)
 					return sp, nil
-				case "e": // edit
+				case key.Matches(msg, sp.keys.Edit):
 					sp.prompt = p.Prompt("Edit Plant", nil)
 					return sp, nil
+				case key.Matches(msg, sp.keys.Archive):
+					sp.prompt = newArchivePrompt(sp.PlantDB, p, sp.refreshItems)
+					return sp, nil
+				case key.Matches(msg, sp.keys.Delete):
+					sp.prompt = newDeletePrompt(sp.PlantDB, p, sp.refreshItems)
+					return sp, nil
+				}
+			}
+
+		case !sp.trash && key.Matches(msg, sp.keys.Tasks...):
+			if sp.list.SettingFilter() || sp.prompt != nil {
+				break
+			}
+			if p := sp.selected(); p != nil {
+				for i, b := range sp.keys.Tasks {
+					if key.Matches(msg, b) {
+						p.toggleEvent(sp.PlantDB.newEvent(customTasks[i].kind, timeNow()))
+					}
 				}
 			}
 
-		case "a":
+		case !sp.trash && key.Matches(msg, sp.keys.Remind, sp.keys.Complete):
 			if sp.list.SettingFilter() || sp.prompt != nil {
 				break
 			}
-			var p *Plant
-			sp.prompt = p.Prompt("Add Plant", func(p *Plant) {
-				sp.PlantDB.Plants = append(sp.PlantDB.Plants, p)
-				// TODO: this would return a command, but I'm not sure what to do with it.
-				_ = sp.list.SetItems(sp.PlantDB.Items())
-			})
+			if p := sp.selected(); p != nil {
+				if key.Matches(msg, sp.keys.Remind) {
+					sp.prompt = newReminderPrompt(p)
+				} else if prompt := newCompleteReminderPrompt(p); prompt != nil {
+					sp.prompt = prompt
+				} else {
+					sp.status = p.Name + " has no open reminders"
+				}
+			}
 			return sp, nil
 
-		case "esc":
+		case !sp.trash && key.Matches(msg, sp.keys.Snooze, sp.keys.Skip):
+			if sp.list.SettingFilter() || sp.prompt != nil {
+				break
+			}
+			if p := sp.selected(); p != nil {
+				kind := eventSkipped
+				if key.Matches(msg, sp.keys.Snooze) {
+					kind = eventSnoozed
+				}
+				sp.prompt = newDeferPrompt(sp.PlantDB, p, kind)
+			}
+			return sp, nil
+
+		case key.Matches(msg, sp.keys.Undo, sp.keys.Redo):
+			if sp.list.SettingFilter() || sp.prompt != nil {
+				break
+			}
+			var err error
+			if key.Matches(msg, sp.keys.Undo) {
+				err = sp.history.undo(sp.PlantDB)
+				sp.status = "Undid the last change"
+			} else {
+				err = sp.history.redo(sp.PlantDB)
+				sp.status = "Redid the last undone change"
+			}
+			if err != nil {
+				sp.status = err.Error()
+			}
+			sp.refreshItems()
+			return sp, nil
+
+		case !sp.trash && key.Matches(msg, sp.keys.Add):
+			if sp.list.SettingFilter() || sp.prompt != nil {
+				break
+			}
+			sp.openAddPrompt()
+			return sp, nil
+
+		case msg.String() == "esc":
+			if sp.trash && !sp.list.IsFiltered() && !sp.list.SettingFilter() && sp.prompt == nil {
+				sp.toggleTrash()
+				return sp, nil
+			}
 			if !sp.list.IsFiltered() && !sp.list.SettingFilter() && sp.prompt == nil {
 				return sp, tea.Quit
 			}
@@ -227,8 +338,8 @@ func (sp *ShowPlants) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return sp, nil
 			}
 
-		default:
-			if !sp.list.SettingFilter() && sp.prompt == nil && keypress == "q" {
+		case key.Matches(msg, sp.keys.Quit):
+			if !sp.list.SettingFilter() && sp.prompt == nil {
 				return sp, tea.Quit
 			}
 		}
@@ -243,10 +354,56 @@ func (sp *ShowPlants) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return sp, cmd
 }
 
-func (sp *ShowPlants) Init() tea.Cmd {
+func (sp *ShowPlants) openAddPrompt() {
+	var p *Plant
+	sp.prompt = p.Prompt("Add Plant", func(p *Plant) {
+		sp.PlantDB.add(p)
+		sp.refreshItems()
+	})
+}
+
+// selected returns the selected plant, or nil if there is none.
+func (sp *ShowPlants) selected() *Plant {
+	items := sp.list.VisibleItems()
+	if len(items) == 0 {
+		return nil
+	}
+	switch item := items[sp.list.Index()].(type) {
+	case plantItem:
+		return item.Plant
+	case *Plant:
+		return item
+	}
 	return nil
 }
 
+// toggleTrash switches between the active and the archived plants.
+func (sp *ShowPlants) toggleTrash() {
+	sp.trash = !sp.trash
+	sp.list.Title = "Your Glorious Plants"
+	if sp.trash {
+		sp.list.Title = "Archived Plants"
+	}
+	sp.list.ResetFilter()
+	sp.refreshItems()
+	sp.list.Select(0)
+}
+
+// refreshItems updates the list after plants have been added, removed or
+// changed.
+func (sp *ShowPlants) refreshItems() {
+	items := sp.PlantDB.Items()
+	if sp.trash {
+		items = sp.PlantDB.ArchivedItems()
+	}
+	// TODO: this would return a command, but I'm not sure what to do with it.
+	_ = sp.list.SetItems(items)
+}
+
+func (sp *ShowPlants) Init() tea.Cmd {
+	return watchDB()
+}
+
 type inputPrompt struct {
 	focusIndex    int
 	inputs        []textinput.Model
@@ -376,6 +533,7 @@ func (p *Plant) Prompt(title string, confirm func(p *Plant)) *inputPrompt {
 		lightLevel  = newLightLevelInput()
 		sourcedFrom = newTextInput("Sourced From", "Propagation")
 		comments    = newTextInput("Comments", "...")
+		adaptive    = newYesNoInput("Adaptive Watering")
 	)
 
 	if p != nil {
@@ -389,6 +547,7 @@ func (p *Plant) Prompt(title string, confirm func(p *Plant)) *inputPrompt {
 		lightLevel = withValue(lightLevel, p.LightLevel.String())
 		sourcedFrom = withValue(sourcedFrom, p.SourcedFrom)
 		comments = withValue(comments, p.Comments)
+		adaptive = withValue(adaptive, formatYesNo(p.AdaptiveWatering))
 	}
 	plantName.Focus()
 	plantName.PromptStyle = focusedStyle
@@ -399,12 +558,26 @@ func (p *Plant) Prompt(title string, confirm func(p *Plant)) *inputPrompt {
 	}
 	return &inputPrompt{
 		title: title,
-		inputs: []textinput.Model{
+		inputs: append([]textinput.Model{
 			plantName, variety, location,
 			wetSoil, watering, fertilizing, potSize,
-			lightLevel, sourcedFrom, comments,
-		},
+			lightLevel, sourcedFrom, comments, adaptive,
+		}, newTaskIntervalInputs(p)...),
 		confirmAction: func(ap *inputPrompt) (tea.Model, error) {
+			watering, err := parseIntervalsInput(ap.inputs[4].Value())
+			if err != nil {
+				return nil, fmt.Errorf("invalid watering intervals: %w", err)
+			}
+			fertilizing, err := parseIntervalsInput(ap.inputs[5].Value())
+			if err != nil {
+				return nil, fmt.Errorf("invalid fertilizing intervals: %w", err)
+			}
+			for i, t := range customTasks {
+				if _, err := parseIntervalsInput(ap.inputs[11+i].Value()); err != nil {
+					return nil, fmt.Errorf("invalid %s intervals: %w", t.kind, err)
+				}
+			}
+
 			p.Name = ap.inputs[0].Value()
 			if p.Name == "" {
 				return nil, fmt.Errorf("name cannot be empty!")
@@ -415,14 +588,8 @@ func (p *Plant) Prompt(title string, confirm func(p *Plant)) *inputPrompt {
 				s, _ := strconv.Atoi(ap.inputs[3].Value())
 				return s
 			}()
-			p.WateringIntervals = func() SeasonalIntervals {
-				si, _ := parseSeasonalIntervals(ap.inputs[4].Value())
-				return si
-			}()
-			p.FertilizingIntervals = func() SeasonalIntervals {
-				si, _ := parseSeasonalIntervals(ap.inputs[5].Value())
-				return si
-			}()
+			p.WateringIntervals = watering
+			p.FertilizingIntervals = fertilizing
 			p.PotSize = func() int {
 				s, _ := strconv.Atoi(ap.inputs[6].Value())
 				return s
@@ -433,6 +600,11 @@ func (p *Plant) Prompt(title string, confirm func(p *Plant)) *inputPrompt {
 			}()
 			p.SourcedFrom = ap.inputs[8].Value()
 			p.Comments = ap.inputs[9].Value()
+			p.AdaptiveWatering, _ = parseYesNo(ap.inputs[10].Value())
+			for i, t := range customTasks {
+				// validated above.
+				_ = p.setTaskIntervals(t.kind, ap.inputs[11+i].Value())
+			}
 			if confirm != nil {
 				confirm(p)
 			}
@@ -458,13 +630,26 @@ func newIntInput(prompt, placeholder string) textinput.Model {
 	return ti
 }
 func newIntervalInput(prompt string) textinput.Model {
-	ti := newTextInput(prompt, "summer/winter, summer, summer/-")
-	ti.Validate = func(s string) error {
-		_, err := parseSeasonalIntervals(s)
-		return err
-	}
+	ti := newTextInput(prompt, "summer/winter, spring/summer/autumn/winter, 12 months; 7, 7-10, 2w")
+	ti.Validate = validateIntervalsInput
 	return ti
 }
+
+// validateIntervalsInput only checks the single intervals, as the number
+// of them is only complete once the input is.
+func validateIntervalsInput(s string) error {
+	for _, split := range strings.Split(s, "/") {
+		if !partialDayRange.MatchString(split) {
+			return fmt.Errorf("invalid interval %q", split)
+		}
+	}
+	return nil
+}
+
+// partialDayRange matches what can be typed on the way to a valid
+// dayRange.
+var partialDayRange = regexp.MustCompile(`^(-|\d*(-\d*)?[dw]?)$`)
+
 func newLightLevelInput() textinput.Model {
 	placeholder := "0 - direct, 1 - bright, 2 - semi-shaded, 3 - shaded"
 	ti := newTextInput("Light Level", placeholder)
@@ -476,9 +661,39 @@ func newLightLevelInput() textinput.Model {
 
 	return ti
 }
+func newYesNoInput(prompt string) textinput.Model {
+	ti := newTextInput(prompt, "yes / no")
+	ti.Validate = func(s string) error {
+		_, err := parseYesNo(s)
+		return err
+	}
+	return ti
+}
+
+// parseYesNo accepts any prefix of yes or no, empty is no.
+func parseYesNo(s string) (bool, error) {
+	switch {
+	case s == "":
+		return false, nil
+	case strings.HasPrefix("yes", strings.ToLower(s)):
+		return true, nil
+	case strings.HasPrefix("no", strings.ToLower(s)):
+		return false, nil
+	default:
+		return false, fmt.Errorf("expected yes or no")
+	}
+}
+
+func formatYesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
 func newDateInput(prompt, placeholder string) textinput.Model {
 	ti := newTextInput(prompt, placeholder)
-	today := time.Now()
+	today := timeNow()
 	ti.SetValue(today.Format("2006-01-02"))
 	ti.Blur() // SetValue seems to also set focus?
 	ti.Validate = func(s string) error {
@@ -503,31 +718,39 @@ func parseInputDate(s string) (time.Time, error) {
 		}
 		expectedSections[i] = val
 	}
-	t, err := time.Parse("2006-01-02", fmt.Sprintf("%04v-%02v-%02v", expectedSections...))
+	t, err := time.ParseInLocation("2006-01-02", fmt.Sprintf("%04v-%02v-%02v", expectedSections...), time.Local)
 	if err != nil {
 		return time.Time{}, err
 	}
-	if t.After(time.Now()) {
+	if t.After(timeNow()) {
 		return time.Time{}, fmt.Errorf("day is in the future")
 	}
 	return t, nil
 }
 
-func newFertilizerPrompt(plant *Plant) *inputPrompt {
+func newFertilizerPrompt(pDB *PlantDB, plant *Plant) *inputPrompt {
 	date := newDateInput("Date", "YYYY-MM-DD")
 	input := newTextInput("Fertilizer Type", "liquid | granular")
 	input.Validate = func(s string) error {
-		if strings.HasPrefix(string(LiquidFertilizer), s) ||
-			strings.HasPrefix(string(GranularFertilizer), s) {
-			return nil
-		}
-		return fmt.Errorf("illegal input")
+		_, err := parseFertilizerType(s)
+		return err
 	}
 	input.Focus()
 	input.PromptStyle = focusedStyle
 	input.TextStyle = focusedStyle
+	// default to what has been used last time.
+	if last, ok := plant.lastEvent(eventFertilized); ok {
+		input.SetValue(string(last.Fertilizer))
+	}
 	return &inputPrompt{
-		inputs:     []textinput.Model{date, input},
+		inputs: []textinput.Model{
+			date, input,
+			newTextInput("Product", "optional"),
+			newTextInput("Amount", "optional"),
+			newTextInput("Dilution", "optional, e.g. 1:100"),
+			newTextInput("NPK", "optional, e.g. 7-3-6"),
+			newTextInput("Note", "optional"),
+		},
 		focusIndex: 1,
 		title:      "Add / Remove Fertilization Event",
 		confirmAction: func(ip *inputPrompt) (tea.Model, error) {
@@ -537,29 +760,32 @@ func newFertilizerPrompt(plant *Plant) *inputPrompt {
 				return nil, fmt.Errorf("invalid date: %v", err)
 			}
 
-			var ft FertilizerType
-			s := ip.inputs[1].Value()
-			if strings.HasPrefix(string(LiquidFertilizer), s) {
-				ft = LiquidFertilizer
-			} else {
-				ft = GranularFertilizer
-			}
-
-			plant.FertilizedAt = toggleEvent(plant.FertilizedAt, date)
-			plant.FertilizedWith = ft
+			e := pDB.newEvent(eventFertilized, date)
+			// should already be verified by the Validate action on the input.
+			e.Fertilizer, _ = parseFertilizerType(ip.inputs[1].Value())
+			e.Product = ip.inputs[2].Value()
+			e.Amount = ip.inputs[3].Value()
+			e.Dilution = ip.inputs[4].Value()
+			e.NPK = ip.inputs[5].Value()
+			e.Note = ip.inputs[6].Value()
+			plant.toggleEvent(e)
 			return nil, nil
 		},
 	}
 }
 
-func newWateringPrompt(plant *Plant) *inputPrompt {
+func newWateringPrompt(pDB *PlantDB, plant *Plant) *inputPrompt {
 	date := newDateInput("Date", "YYYY-MM-DD")
 	date.Focus()
 	date.PromptStyle = focusedStyle
 	date.TextStyle = focusedStyle
 	return &inputPrompt{
-		inputs: []textinput.Model{date},
-		title:  "Add / Remove Watering Event",
+		inputs: []textinput.Model{
+			date,
+			newTextInput("Amount", "optional"),
+			newTextInput("Note", "optional"),
+		},
+		title: "Add / Remove Watering Event",
 		confirmAction: func(ip *inputPrompt) (tea.Model, error) {
 			date, err := parseInputDate(ip.inputs[0].Value())
 			if err != nil {
@@ -567,20 +793,30 @@ func newWateringPrompt(plant *Plant) *inputPrompt {
 				return nil, fmt.Errorf("invalid date: %v", err)
 			}
 
-			plant.WateredAt = toggleEvent(plant.WateredAt, date)
+			e := pDB.newEvent(eventWatered, date)
+			e.Amount = ip.inputs[1].Value()
+			e.Note = ip.inputs[2].Value()
+			plant.toggleEvent(e)
 			return nil, nil
 		},
 	}
 }
 
-func newRepottingPrompt(plant *Plant) *inputPrompt {
+func newRepottingPrompt(pDB *PlantDB, plant *Plant) *inputPrompt {
 	date := newDateInput("Date", "YYYY-MM-DD")
 	newSize := newIntInput("New Pot Size", "in cm")
 	newSize.Focus()
 	newSize.PromptStyle = focusedStyle
 	newSize.TextStyle = focusedStyle
+	material := newTextInput("Pot Material", "optional, e.g. terracotta")
+	soil := newTextInput("Soil Mix", "optional, e.g. 2 parts soil, 1 part perlite")
+	// most likely the same as last time.
+	if last, ok := plant.lastEvent(eventRepotted); ok {
+		material.SetValue(last.PotMaterial)
+		soil.SetValue(last.SoilMix)
+	}
 	return &inputPrompt{
-		inputs:     []textinput.Model{date, newSize},
+		inputs:     []textinput.Model{date, newSize, material, soil, newTextInput("Note", "optional")},
 		focusIndex: 1,
 		title:      "Add / Remove Repotting Event",
 		confirmAction: func(ip *inputPrompt) (tea.Model, error) {
@@ -590,93 +826,254 @@ func newRepottingPrompt(plant *Plant) *inputPrompt {
 				return nil, fmt.Errorf("invalid date: %v", err)
 			}
 
-			newSize, _ := strconv.Atoi(ip.inputs[0].Value())
-			plant.PotSize = newSize
-			plant.RepottedAt = toggleEvent(plant.RepottedAt, date)
+			e := pDB.newEvent(eventRepotted, date)
+			e.PotSize, _ = strconv.Atoi(ip.inputs[1].Value())
+			e.PotMaterial = ip.inputs[2].Value()
+			e.SoilMix = ip.inputs[3].Value()
+			e.Note = ip.inputs[4].Value()
+			plant.toggleEvent(e)
 			return nil, nil
 		},
 	}
 }
 
 func main() {
-	home, err := os.UserHomeDir()
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	cfg, args, err := loadConfig(args)
 	if err != nil {
-		log.Fatalf("could not determine home dir: %v", err)
+		fmt.Println(err)
+		return 2
 	}
+	asOf = cfg.asOf
+	applyTheme(cfg.Theme)
+	applyTasks(cfg.Tasks)
 
-	pDB, err := readDBFile(path.Join(home, ".config", "positive_hydration.json"))
+	pDB, err := openDB(cfg.DB, cfg.scheduler())
 	if err != nil {
 		fmt.Println("could not read DB file: ", err)
-		os.Exit(2)
+		return 2
 	}
+	pDB.actor = cfg.Actor
+
+	if len(args) > 0 {
+		return runCommand(pDB, args)
+	}
+
 	defer func() {
 		if err := pDB.Close(); err != nil {
 			fmt.Printf("Could not close DB: %v\n", err)
 		}
 	}()
 
-	if _, err := tea.NewProgram(newShowPlants(pDB)).Run(); err != nil {
+	h, err := newHistory(pDB, cfg.Undo.Limit, cfg.undoLocation())
+	if err != nil {
+		fmt.Println(err)
+	}
+	p := tea.NewProgram(newShowPlants(pDB, newKeyMap(cfg.Keys, cfg.Tasks), h))
+
+	// quit the program on termination signals, so that the DB gets
+	// flushed by the deferred Close above.
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	defer signal.Stop(sigs)
+	go func() {
+		if _, ok := <-sigs; ok {
+			p.Quit()
+		}
+	}()
+
+	if _, err := p.Run(); err != nil {
 		fmt.Println("Error running program:", err)
-		os.Exit(1)
+		return 1
+	}
+	if err := h.save(); err != nil {
+		fmt.Println(err)
 	}
+	return 0
 }
 
-func readDBFile(location string) (*PlantDB, error) {
-	pDB := &PlantDB{
-		dbLocation: location,
+func runCommand(pDB *PlantDB, args []string) int {
+	cmd, ok := lookupCommand(args[0])
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", args[0])
+		_ = printUsage(pDB, nil)
+		return 2
 	}
-	data, err := os.ReadFile(location)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return pDB, nil
+
+	if err := cmd.run(pDB, args[1:]); err != nil {
+		_ = pDB.storage.Close()
+		var status exitStatus
+		if errors.As(err, &status) {
+			return int(status)
 		}
-		return nil, fmt.Errorf("could not read DB file: %w", err)
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 1
 	}
 
-	if err := json.Unmarshal(data, pDB); err != nil {
-		return nil, fmt.Errorf("malformatted DB file: %w", err)
+	if cmd.readOnly {
+		_ = pDB.storage.Close()
+		return 0
+	}
+	if err := pDB.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "Could not close DB: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// openDB opens the storage at location and loads the DB from it.
+func openDB(location string, sched *Scheduler) (*PlantDB, error) {
+	storage, err := openStorage(location)
+	if err != nil {
+		return nil, err
+	}
+	pDB := &PlantDB{
+		storage: storage,
+		sched:   sched,
+	}
+	if err := pDB.reload(); err != nil {
+		_ = storage.Close()
+		return nil, err
 	}
 	return pDB, nil
 }
 
-func (pDB *PlantDB) Close() error {
+// errDBModified is returned when saving if the DB has been changed by
+// someone else since it has last been loaded.
+var errDBModified = errors.New("DB has been modified by someone else")
+
+// reload replaces all plants with the ones currently in the storage.
+func (pDB *PlantDB) reload() error {
+	stored, err := pDB.storage.Load()
+	if err != nil {
+		return err
+	}
+	pDB.Plants = stored.Plants
+	// whatever has been loaded has been migrated to the current version.
+	pDB.Version = dbVersion
+	pDB.markSaved()
+	if stored.migrated {
+		// write the migrated DB right away, otherwise it would be migrated
+		// again on every load and e.g. get new plant IDs every time.
+		pDB.saved = nil
+		return pDB.Save()
+	}
+	return nil
+}
+
+// markSaved records the current state as the one in the storage.
+func (pDB *PlantDB) markSaved() {
 	pDB.normalise()
+	// only used to detect changes, an error would just lead to a save.
+	pDB.saved, _ = json.Marshal(pDB)
+}
 
+// dirty reports whether there are changes that have not been saved yet.
+func (pDB *PlantDB) dirty() bool {
+	pDB.normalise()
 	data, err := json.Marshal(pDB)
-	if err != nil {
-		return fmt.Errorf("could not marshal plantDB to JSON: %w", err)
+	return err != nil || !bytes.Equal(data, pDB.saved)
+}
+
+// Close saves the DB and closes the storage. Changes made by others in
+// the meantime are merged, as there's nobody left to ask.
+func (pDB *PlantDB) Close() error {
+	err := pDB.Save()
+	if errors.Is(err, errDBModified) {
+		err = pDB.mergeFromStorage()
+	}
+	if cErr := pDB.storage.Close(); err == nil {
+		err = cErr
+	}
+	return err
+}
+
+// Save writes the DB to the storage if it has changed since it has last
+// been loaded or saved. If someone else has modified the DB in the
+// meantime, errDBModified is returned.
+func (pDB *PlantDB) Save() error {
+	return pDB.save(false)
+}
+
+// save is Save, but with force it overwrites external modifications.
+func (pDB *PlantDB) save(force bool) error {
+	if !pDB.dirty() && !force {
+		return nil
+	}
+	if err := pDB.storage.Save(pDB, force); err != nil {
+		return err
+	}
+	pDB.markSaved()
+	return nil
+}
+
+// addEvent adds an event to the plant. If there are no other unsaved
+// changes, only the event is appended to the storage instead of saving
+// the whole DB.
+func (pDB *PlantDB) addEvent(p *Plant, e CareEvent) error {
+	found := false
+	for i := range pDB.Plants {
+		if pDB.Plants[i] == p {
+			found = true
+		}
+	}
+	if !found {
+		return fmt.Errorf("plant %q is not in the DB", p.Name)
 	}
 
-	if err := os.WriteFile(pDB.dbLocation, data, 0600); err != nil {
-		return fmt.Errorf("could not write DB file: %w", err)
+	dirty := pDB.dirty()
+	before, err := plantRow(p)
+	if err != nil {
+		return err
+	}
+	e = p.appendEvent(e)
+	// appending can change the plant as well, e.g. its pot size.
+	if after, err := plantRow(p); dirty || err != nil || after != before {
+		return pDB.Save()
 	}
 
+	if err := pDB.storage.AppendEvent(p.ID, e); err != nil {
+		return err
+	}
+	pDB.markSaved()
 	return nil
 }
 
 func (pDB *PlantDB) normalise() {
 	for _, plant := range pDB.Plants {
-		sort.Slice(plant.WateredAt, func(i, j int) bool {
-			return plant.WateredAt[i].Before(plant.WateredAt[j])
-		})
+		sortEvents(plant.History)
 
 		// makes sure there's only one watering-entry per day.
-		var times []time.Time
-		for _, watered := range plant.WateredAt {
-			if len(times) > 0 &&
-				times[len(times)-1].Year() == watered.Year() &&
-				times[len(times)-1].YearDay() == watered.YearDay() {
-				continue
+		var events []CareEvent
+		var lastWatered time.Time
+		for _, e := range plant.History {
+			if e.Kind == eventWatered {
+				if !lastWatered.IsZero() && sameDay(lastWatered, e.Time) {
+					continue
+				}
+				lastWatered = e.Time
 			}
-			times = append(times, watered)
+			events = append(events, e)
 		}
-		plant.WateredAt = times
+		plant.History = events
 	}
 }
 
 type PlantDB struct {
-	dbLocation string
-	Plants     []*Plant `json:"plants"`
+	// Version is the format version of the DB, see migrations.
+	Version int `json:"version"`
+	storage Storage
+	sched   *Scheduler
+	// actor is recorded as the one who did new events.
+	actor string
+	// saved is the content of the DB as of the last load / save.
+	saved []byte
+	// migrated is set when the DB has been migrated while loading.
+	migrated bool
+	Plants   []*Plant `json:"plants"`
 }
 
 type NoPlantsEntry struct{}
@@ -692,44 +1089,92 @@ func (NoPlantsEntry) Description() string {
 }
 
 func (p *PlantDB) Items() []list.Item {
-	if len(p.Plants) == 0 {
-		return []list.Item{NoPlantsEntry{}}
+	type scheduledPlant struct {
+		plant    *Plant
+		watering *window
 	}
-	items := make([]list.Item, 0, len(p.Plants))
+	var plants []scheduledPlant
 	for _, plant := range p.Plants {
-		items = append(items, plant)
+		if plant.archived() {
+			continue
+		}
+		sp := scheduledPlant{plant: plant}
+		if w, ok := plant.scheduled(p.sched, eventWatered); ok {
+			sp.watering = &w
+		}
+		plants = append(plants, sp)
+	}
+	if len(plants) == 0 {
+		return []list.Item{NoPlantsEntry{}}
 	}
 
-	// sort plants by next watering day.
-	sort.Slice(items, func(i, j int) bool {
-		di, iok := scheduledIn(last(items[i].(*Plant).WateredAt), items[i].(*Plant).WateringIntervals)
-		dj, jok := scheduledIn(last(items[j].(*Plant).WateredAt), items[j].(*Plant).WateringIntervals)
-		if iok && jok {
-			return di < dj
-		} else if jok {
-			return true
+	// sort plants by the window of the next watering, the ones that need
+	// it first.
+	sort.SliceStable(plants, func(i, j int) bool {
+		wi, wj := plants[i].watering, plants[j].watering
+		switch {
+		case wi != nil && wj != nil && wi.Closes != wj.Closes:
+			return wi.Closes < wj.Closes
+		case wi != nil && wj != nil:
+			return wi.Opens < wj.Opens
+		default:
+			return wj == nil && wi != nil
 		}
-		return false
 	})
 
+	items := make([]list.Item, 0, len(plants))
+	for _, sp := range plants {
+		items = append(items, plantItem{Plant: sp.plant, sched: p.sched})
+	}
 	return items
 }
 
+// plantItem is an active plant in the list. Its description also tells
+// whether the plant can or must be watered.
+type plantItem struct {
+	*Plant
+	sched *Scheduler
+}
+
+func (pi plantItem) Description() string {
+	var due string
+	if w, ok := pi.scheduled(pi.sched, eventWatered); ok {
+		switch {
+		case w.must():
+			due = " · must water"
+		case w.open():
+			due = " · can water"
+		}
+	}
+	return pi.Plant.Description() + due
+}
+
 type Plant struct {
-	Name                 string            `json:"name"`
-	Variety              string            `json:"variety"`
-	Location             string            `json:"location"`
-	WateredAt            []time.Time       `json:"watered_at"`
-	FertilizedAt         []time.Time       `json:"fertilized_at"`
-	FertilizedWith       FertilizerType    `json:"fertilizer_type,omitempty"`
+	// ID identifies the plant, unlike the name it is unique and never
+	// changes.
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Variety  string `json:"variety"`
+	Location string `json:"location"`
+	// History contains all care events, oldest first.
+	History              []CareEvent       `json:"events"`
 	PotSize              int               `json:"pot_size"`
-	RepottedAt           []time.Time       `json:"repotted_at"`
 	WateringIntervals    SeasonalIntervals `json:"watering_intervals"`
 	WetSoilDepth         int               `json:"wet_soil_depth"`
 	FertilizingIntervals SeasonalIntervals `json:"fertilizing_intervals"`
-	LightLevel           LightLevel        `json:"light_level,omitempty"`
-	Comments             string            `json:"comments"`
-	SourcedFrom          string            `json:"sourced_from"`
+	// AdaptiveWatering schedules watering by the intervals at which the
+	// plant has actually been watered recently.
+	AdaptiveWatering bool `json:"adaptive_watering,omitempty"`
+	// TaskIntervals are the intervals of custom tasks that differ from
+	// the ones in the config.
+	TaskIntervals map[eventKind]SeasonalIntervals `json:"task_intervals,omitempty"`
+	LightLevel    LightLevel                      `json:"light_level,omitempty"`
+	Comments      string                          `json:"comments"`
+	SourcedFrom   string                          `json:"sourced_from"`
+	ArchivedAt    *time.Time                      `json:"archived_at,omitempty"`
+	ArchiveReason string                          `json:"archive_reason,omitempty"`
+	// Reminders are ordered by their due date.
+	Reminders []Reminder `json:"reminders,omitempty"`
 }
 
 type FertilizerType string
@@ -739,6 +1184,21 @@ const (
 	GranularFertilizer FertilizerType = "granular"
 )
 
+// parseFertilizerType accepts any prefix of the fertilizer types. An
+// empty string is an unknown type.
+func parseFertilizerType(s string) (FertilizerType, error) {
+	switch {
+	case s == "":
+		return "", nil
+	case strings.HasPrefix(string(LiquidFertilizer), s):
+		return LiquidFertilizer, nil
+	case strings.HasPrefix(string(GranularFertilizer), s):
+		return GranularFertilizer, nil
+	default:
+		return "", fmt.Errorf("invalid fertilizer type %q, expected liquid or granular", s)
+	}
+}
+
 type LightLevel string
 
 var lightLevels = [...]LightLevel{
@@ -758,41 +1218,26 @@ func (l *LightLevel) String() string {
 	return "unknown"
 }
 
+// UnmarshalJSON makes sure only known light levels are read. Before DB
+// version 1, light levels were stored as integers, see
+// migrateLightLevelNames.
 func (l *LightLevel) UnmarshalJSON(b []byte) error {
-	var s int
+	var s string
 	if err := json.Unmarshal(b, &s); err != nil {
 		return err
 	}
-	if s == 0 {
+	if s == "" {
 		*l = ""
 		return nil
 	}
 
-	if s < 1 || s >= len(lightLevels) {
-		return fmt.Errorf("invalid light level: %q", s)
+	level, err := parseLightLevel(s)
+	if err != nil {
+		return err
 	}
-
-	*l = lightLevels[s-1]
+	*l = level
 	return nil
 }
-func (l *LightLevel) MarshalJSON() ([]byte, error) {
-	if l == nil {
-		return nil, fmt.Errorf("no light level to marshal")
-	}
-
-	var level int
-	for i := range lightLevels {
-		if lightLevels[i] == *l {
-			level = i + 1
-			break
-		}
-	}
-	if level == 0 {
-		return nil, nil
-	}
-
-	return json.Marshal(level)
-}
 
 func parseLightLevel(s string) (LightLevel, error) {
 	i, err := strconv.Atoi(s)
@@ -804,90 +1249,225 @@ func parseLightLevel(s string) (LightLevel, error) {
 		}
 		return "", fmt.Errorf("invalid light level: %v", s)
 	}
-	if i > len(lightLevels)-1 {
+	if i < 0 || i > len(lightLevels)-1 {
 		return "", fmt.Errorf("light level out of range")
 	}
 	return lightLevels[i], nil
 }
 
+// SeasonalIntervals are the intervals in days of a recurring task,
+// either for summer and winter, for four seasons or for every month. An
+// interval of 0 means that the task isn't needed in that time.
 type SeasonalIntervals struct {
-	Summer int `json:"summer"`
-	Winter int `json:"winter"`
+	Summer dayRange `json:"summer"`
+	Winter dayRange `json:"winter"`
+	// Spring and Autumn are only set in profiles with four seasons,
+	// otherwise the summer interval is used for them.
+	Spring *dayRange `json:"spring,omitempty"`
+	Autumn *dayRange `json:"autumn,omitempty"`
+	// Months has the intervals of every month, starting with January.
+	// If set, the seasons are not used.
+	Months []dayRange `json:"months,omitempty"`
 }
 
-func parseSeasonalIntervals(s string) (SeasonalIntervals, error) {
-	splits := strings.Split(s, "/")
-	if len(splits) > 2 {
-		return SeasonalIntervals{}, fmt.Errorf("invalid seasonal interval")
+// dayRange is an interval that can vary, the task can be done after Min
+// days and needs to be done after Max days.
+type dayRange struct {
+	Min, Max int
+}
+
+// parseDayRange parses a number of days like 7, a range like 7-10, or
+// either in weeks, like 2w or 1-2w. "-" or an empty string is no
+// interval.
+func parseDayRange(s string) (dayRange, error) {
+	if s == "-" || s == "" {
+		return dayRange{}, nil
 	}
-	if len(splits) == 0 {
-		return SeasonalIntervals{}, fmt.Errorf("no interval given")
+	unit := 1
+	if strings.HasSuffix(s, "w") {
+		s, unit = strings.TrimSuffix(s, "w"), 7
+	} else {
+		s = strings.TrimSuffix(s, "d")
 	}
-	summer, err := strconv.Atoi(splits[0])
+	from, to, isRange := strings.Cut(s, "-")
+	min, err := strconv.Atoi(from)
 	if err != nil {
-		return SeasonalIntervals{}, fmt.Errorf("invalid summer interval: %w", err)
+		return dayRange{}, err
+	}
+	max := min
+	if isRange {
+		if max, err = strconv.Atoi(to); err != nil {
+			return dayRange{}, err
+		}
+	}
+	if min < 0 || max < min {
+		return dayRange{}, fmt.Errorf("invalid range %s", s)
+	}
+	return dayRange{Min: min * unit, Max: max * unit}, nil
+}
+
+func (r dayRange) String() string {
+	switch {
+	case r.Max == 0:
+		return "-"
+	case r.Min == r.Max:
+		return strconv.Itoa(r.Min)
+	default:
+		return strconv.Itoa(r.Min) + "-" + strconv.Itoa(r.Max)
 	}
+}
 
-	if len(splits) == 1 {
-		return SeasonalIntervals{
-			Summer: summer,
-			Winter: summer,
-		}, nil
+// MarshalJSON writes fixed intervals as plain numbers, like they were
+// before ranges.
+func (r dayRange) MarshalJSON() ([]byte, error) {
+	if r.Min == r.Max {
+		return json.Marshal(r.Min)
 	}
+	return json.Marshal(struct {
+		Min int `json:"min"`
+		Max int `json:"max"`
+	}{r.Min, r.Max})
+}
 
-	var winter int
-	if splits[1] == "-" || splits[1] == "" {
-		winter = 0
-	} else {
-		winter, err = strconv.Atoi(splits[1])
+func (r *dayRange) UnmarshalJSON(b []byte) error {
+	var days int
+	if err := json.Unmarshal(b, &days); err == nil {
+		*r = dayRange{Min: days, Max: days}
+		return nil
+	}
+	var v struct {
+		Min int `json:"min"`
+		Max int `json:"max"`
+	}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	*r = dayRange{Min: v.Min, Max: v.Max}
+	return nil
+}
+
+// parseSeasonalIntervals parses intervals as summer/winter,
+// spring/summer/autumn/winter or one per month starting with January,
+// see parseDayRange for the single intervals. A single interval is used
+// all year.
+func parseSeasonalIntervals(s string) (SeasonalIntervals, error) {
+	splits := strings.Split(s, "/")
+	if splits[0] == "" {
+		return SeasonalIntervals{}, fmt.Errorf("no interval given")
+	}
+	days := make([]dayRange, len(splits))
+	for i, split := range splits {
+		d, err := parseDayRange(split)
 		if err != nil {
-			return SeasonalIntervals{}, fmt.Errorf("invalid winter interval: %w", err)
+			return SeasonalIntervals{}, fmt.Errorf("invalid interval %q: %w", split, err)
 		}
+		days[i] = d
+	}
+
+	switch len(days) {
+	case 1:
+		return SeasonalIntervals{Summer: days[0], Winter: days[0]}, nil
+	case 2:
+		return SeasonalIntervals{Summer: days[0], Winter: days[1]}, nil
+	case 4:
+		return SeasonalIntervals{Spring: &days[0], Summer: days[1], Autumn: &days[2], Winter: days[3]}, nil
+	case 12:
+		return SeasonalIntervals{Months: days}, nil
+	default:
+		return SeasonalIntervals{}, fmt.Errorf("expected 1, 2, 4 or 12 intervals, got %d", len(days))
 	}
+}
 
-	return SeasonalIntervals{
-		Summer: summer,
-		Winter: winter,
-	}, nil
+// parseIntervalsInput is parseSeasonalIntervals for form inputs, where
+// an empty value means no intervals.
+func parseIntervalsInput(s string) (SeasonalIntervals, error) {
+	if s == "" {
+		return SeasonalIntervals{}, nil
+	}
+	return parseSeasonalIntervals(s)
 }
 
 func (si SeasonalIntervals) String() string {
-	var s strings.Builder
-	s.WriteString(strconv.Itoa(si.Summer) + "/")
-	if si.Winter != 0 {
-		s.WriteString(strconv.Itoa(si.Winter))
-	} else {
-		s.WriteString("-")
+	join := func(days ...dayRange) string {
+		parts := make([]string, len(days))
+		for i, d := range days {
+			parts[i] = d.String()
+		}
+		return strings.Join(parts, "/")
+	}
+	switch {
+	case si.Months != nil:
+		return join(si.Months...)
+	case si.Spring != nil || si.Autumn != nil:
+		return join(si.spring(), si.Summer, si.autumn(), si.Winter)
+	case si.Summer.Max == 0:
+		return "0/" + si.Winter.String()
+	default:
+		return join(si.Summer, si.Winter)
 	}
-	return s.String()
 }
 
-func (p Plant) Render(includeStats bool) string {
+func (si SeasonalIntervals) spring() dayRange {
+	if si.Spring == nil {
+		return si.Summer
+	}
+	return *si.Spring
+}
+
+func (si SeasonalIntervals) autumn() dayRange {
+	if si.Autumn == nil {
+		return si.Summer
+	}
+	return *si.Autumn
+}
+
+// unset returns true if the task is never needed.
+func (si SeasonalIntervals) unset() bool {
+	for _, d := range append([]dayRange{si.Summer, si.Winter, si.spring(), si.autumn()}, si.Months...) {
+		if d.Max != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (p Plant) Render(sched *Scheduler, includeStats bool) string {
 	parts := []string{
 		titleStyle.Render(p.Name),
 		boxed.Render(p.Overview()),
 		titleStyle.Render("Calendar Overview"),
-		boxed.Render(calendar.NewRender(p.Events()...)),
+		boxed.Render(calendar.NewRender(timeNow(), p.Events()...)),
 	}
 	if includeStats {
-		parts = append(parts, p.renderStatistics())
+		parts = append(parts, p.renderStatistics(sched))
+		if _, ok := p.lastEvent(eventRepotted); ok {
+			parts = append(parts, p.renderRepotting())
+		}
+		if len(p.History) > 0 {
+			parts = append(parts, p.renderHistory())
+		}
 	}
 	return lipgloss.JoinVertical(lipgloss.Center, parts...)
 }
 
 func (p Plant) Clone() *Plant {
+	var taskIntervals map[eventKind]SeasonalIntervals
+	for kind, si := range p.TaskIntervals {
+		if taskIntervals == nil {
+			taskIntervals = map[eventKind]SeasonalIntervals{}
+		}
+		taskIntervals[kind] = si
+	}
 	return &Plant{
 		Name:                 p.Name,
 		Variety:              p.Variety,
 		Location:             p.Location,
-		WateredAt:            nil,
-		FertilizedAt:         nil,
-		FertilizedWith:       p.FertilizedWith,
 		PotSize:              p.PotSize,
-		RepottedAt:           nil,
 		WateringIntervals:    p.WateringIntervals,
 		WetSoilDepth:         p.WetSoilDepth,
 		FertilizingIntervals: p.FertilizingIntervals,
+		AdaptiveWatering:     p.AdaptiveWatering,
+		TaskIntervals:        taskIntervals,
 		LightLevel:           p.LightLevel,
 		Comments:             p.Comments,
 		SourcedFrom:          p.SourcedFrom,
@@ -901,34 +1481,34 @@ func copyTimes(ts []time.Time) []time.Time {
 }
 
 func (p Plant) Events() []calendar.Event {
-	var (
-		wateredColor    = lipgloss.Color("#1d0ed1")
-		fertilizedColor = lipgloss.Color("#004b26")
-		repottedColor   = lipgloss.Color("#512013")
-	)
-
 	events := make(map[time.Time]lipgloss.Style)
-	for _, w := range p.WateredAt {
-		events[w.Truncate(24*time.Hour)] = lipgloss.NewStyle().Background(wateredColor)
-	}
-	for _, w := range p.FertilizedAt {
-		t := w.Truncate(24 * time.Hour)
-		if _, ok := events[t]; ok {
-			events[t].Underline(true).Background(fertilizedColor)
-		} else {
-			events[t] = lipgloss.NewStyle().Background(fertilizedColor)
-
+	// later kinds take precedence, any fertilizing actions on the same
+	// day as a repotting would not get displayed. But please don't
+	// fertilize a plant that just got into fresh soil.
+	for _, kind := range allEventKinds() {
+		for _, e := range p.History {
+			if e.Kind != kind {
+				continue
+			}
+			t := startOfDay(e.Time)
+			style, ok := events[t]
+			if ok {
+				style = style.Underline(true)
+			}
+			style = style.Background(eventColor(kind))
+			// days with notes stand out, the notes are listed in the
+			// history.
+			if e.Note != "" {
+				style = style.Bold(true)
+			}
+			events[t] = style
 		}
 	}
-	for _, w := range p.RepottedAt {
-		t := w.Truncate(24 * time.Hour)
-		if _, ok := events[t]; ok {
-			// Any fertilizing actions on the same day would not get displayed.
-			// But please don't fertilize a plant that just got into fresh soil.
-			events[t].Underline(true).Background(repottedColor)
-		} else {
-			events[t] = lipgloss.NewStyle().Background(repottedColor)
-		}
+
+	// open reminders keep the background of the events on the same day.
+	for _, i := range p.openReminders() {
+		t := startOfDay(p.Reminders[i].Due)
+		events[t] = events[t].Foreground(reminderColor).Bold(true)
 	}
 
 	e := make([]calendar.Event, 0, len(events))
@@ -943,30 +1523,40 @@ func (p Plant) Overview() string {
 	t1Rows := []table.Row{
 		{"Variety", p.Variety},
 		{"Location", p.Location},
-		{"Last Watered", formatTimeInDays(last(p.WateredAt))},
-		{"Last Fertilized", formatTimeInDays(last(p.FertilizedAt))},
+		{"Last Watered", formatTimeInDays(last(p.times(eventWatered)))},
+		{"Last Fertilized", formatTimeInDays(last(p.times(eventFertilized)))},
 	}
 
 	t2Rows := []table.Row{
-		{"Watering", p.WateringIntervals.String()},
+		{"Watering", p.formatWateringIntervals()},
 		{"Fertilizing", p.FertilizingIntervals.String()},
 		{"Light Level", p.LightLevel.String()},
 		{"Soil Dryness", strconv.Itoa(p.WetSoilDepth) + "cm"},
 	}
 
 	additionalRows := []table.Row{}
-	if lastRepot := last(p.RepottedAt); !lastRepot.IsZero() {
+	if lastRepot := last(p.times(eventRepotted)); !lastRepot.IsZero() {
 		additionalRows = append(additionalRows, table.Row{"Last Repotted", formatTimeInDays(lastRepot)})
 	}
 	if p.PotSize != 0 {
 		additionalRows = append(additionalRows, table.Row{"Pot Size", p.formatPotSize()})
 	}
-	if p.FertilizedWith != "" {
-		additionalRows = append(additionalRows, table.Row{"Fertilizer", string(p.FertilizedWith)})
+	if last, ok := p.lastEvent(eventFertilized); ok && last.fertilizer() != "" {
+		additionalRows = append(additionalRows, table.Row{"Fertilizer", last.fertilizer()})
 	}
 	if p.SourcedFrom != "" {
 		additionalRows = append(additionalRows, table.Row{"Sourced From", p.SourcedFrom})
 	}
+	for _, i := range p.openReminders() {
+		additionalRows = append(additionalRows, table.Row{"Reminder", p.Reminders[i].String()})
+	}
+	if p.archived() {
+		archived := formatTimeInDays(*p.ArchivedAt)
+		if p.ArchiveReason != "" {
+			archived += " (" + p.ArchiveReason + ")"
+		}
+		additionalRows = append(additionalRows, table.Row{"Archived", archived})
+	}
 
 	for i, addR := range additionalRows {
 		if i%2 == 0 {
@@ -1011,6 +1601,13 @@ func (p Plant) Overview() string {
 	return lipgloss.JoinVertical(lipgloss.Top, elements...)
 }
 
+func (p Plant) formatWateringIntervals() string {
+	if p.AdaptiveWatering {
+		return p.WateringIntervals.String() + " (adaptive)"
+	}
+	return p.WateringIntervals.String()
+}
+
 func (p Plant) formatPotSize() string {
 	if p.PotSize == 0 {
 		return "unknown"
@@ -1027,68 +1624,143 @@ func (p Plant) Title() string {
 }
 func (p Plant) Description() string {
 	return "Location: " + p.Location + "\n" +
-		"Last Watered: " + formatTimeInDays(last(p.WateredAt))
-}
-
-func toggleEvent(events []time.Time, newEvent time.Time) []time.Time {
-	ny, nm, nd := newEvent.Date()
-	for i, pastEvent := range events {
-		py, pm, pd := pastEvent.Date()
-		if ny == py && nm == pm && nd == pd {
-			return append(events[:i], events[i+1:]...)
-		}
-	}
-
-	return append(events, newEvent)
+		"Last Watered: " + formatTimeInDays(last(p.times(eventWatered)))
 }
 
-func (p Plant) renderStatistics() string {
-	sort.Slice(p.WateredAt, func(i, j int) bool {
-		return p.WateredAt[i].Before(p.WateredAt[j])
-	})
+func (p Plant) renderStatistics(sched *Scheduler) string {
+	watered, fertilized := p.times(eventWatered), p.times(eventFertilized)
 
 	s := table.DefaultStyles()
 	s.Header = s.Header.
 		BorderStyle(lipgloss.NormalBorder()).
-		BorderForeground(lipgloss.Color("240")).
+		BorderForeground(borderColor).
 		BorderBottom(true).Bold(false).Align(lipgloss.Left)
 	s.Selected = s.Cell.Padding(0)
 
 	t1Rows := []table.Row{
-		{"Next Watering Day", p.nextScheduledWateringDay()},
-		{"Last Watered", formatTimeInDays(last(p.WateredAt))},
-		{"60 Days Avg Interval", formatAverage(average(p.WateredAt, 60))},
-		{"Total Avg Interval", formatAverage(average(p.WateredAt, 0))},
+		{"Next Watering Day", p.nextScheduledWateringDay(sched)},
+		{"Last Watered", formatTimeInDays(last(watered))},
+		{"60 Days Avg Interval", formatAverage(average(watered, 60))},
+		{"Total Avg Interval", formatAverage(average(watered, 0))},
+		{"Checks, Still Moist", strconv.Itoa(len(since(p.times(eventChecked), 60))) + " in 60 days"},
 	}
 
 	t2Rows := []table.Row{
-		{"Next Fertilizing Day", p.nextScheduledFertilizingDay()},
-		{"Last Fertilized", formatTimeInDays(last(p.FertilizedAt))},
-		{"90 Days Avg Interval", formatAverage(average(p.FertilizedAt, 90))},
-		{"Total Avg Interval", formatAverage(average(p.FertilizedAt, 0))},
+		{"Next Fertilizing Day", p.nextScheduledFertilizingDay(sched)},
+		{"Last Fertilized", formatTimeInDays(last(fertilized))},
+		{"90 Days Avg Interval", formatAverage(average(fertilized, 90))},
+		{"Total Avg Interval", formatAverage(average(fertilized, 0))},
+	}
+
+	stats := lipgloss.JoinHorizontal(lipgloss.Center,
+		table.New(
+			table.WithColumns([]table.Column{
+				{Title: "Watering Stats", Width: 24},
+				{Title: "", Width: 17},
+			}),
+			table.WithRows(t1Rows),
+			table.WithHeight(len(t1Rows)),
+			table.WithStyles(s),
+		).View(),
+		table.New(
+			table.WithColumns([]table.Column{
+				{Title: "Fertilizing Stats", Width: 24},
+				{Title: "", Width: 17},
+			}),
+			table.WithRows(t2Rows),
+			table.WithStyles(s),
+			table.WithHeight(len(t2Rows)),
+		).View(),
+	)
+	parts := []string{stats}
+	if suggestion := p.intervalSuggestion(sched); suggestion != "" {
+		parts = append(parts, "", suggestion)
+	}
+	if len(customTasks) > 0 {
+		parts = append(parts, "", p.renderTaskStatistics(sched, s))
+	}
+	if len(fertilized) == 0 {
+		return boxed.Render(lipgloss.JoinVertical(lipgloss.Left, parts...))
 	}
 
-	return boxed.Render(
-		lipgloss.JoinHorizontal(lipgloss.Center,
-			table.New(
-				table.WithColumns([]table.Column{
-					{Title: "Watering Stats", Width: 24},
-					{Title: "", Width: 17},
-				}),
-				table.WithRows(t1Rows),
-				table.WithHeight(len(t1Rows)),
-				table.WithStyles(s),
-			).View(),
-			table.New(
-				table.WithColumns([]table.Column{
-					{Title: "Fertilizing Stats", Width: 24},
-					{Title: "", Width: 17},
-				}),
-				table.WithRows(t2Rows),
-				table.WithStyles(s),
-				table.WithHeight(len(t2Rows)),
-			).View(),
-		))
+	var t3Rows []table.Row
+	for _, f := range p.fertilizerStats() {
+		t3Rows = append(t3Rows, table.Row{
+			f.name, strconv.Itoa(len(f.times)),
+			formatTimeInDays(last(f.times)), formatAverage(average(f.times, 0)),
+		})
+	}
+	return boxed.Render(lipgloss.JoinVertical(lipgloss.Left, append(parts,
+		"",
+		table.New(
+			table.WithColumns([]table.Column{
+				{Title: "Fertilizer", Width: 24},
+				{Title: "Times Used", Width: 17},
+				{Title: "Last Used", Width: 24},
+				{Title: "Avg Interval", Width: 17},
+			}),
+			table.WithRows(t3Rows),
+			table.WithStyles(s),
+			table.WithHeight(len(t3Rows)),
+		).View(),
+	)...))
+}
+
+type fertilizerStats struct {
+	name  string
+	times []time.Time
+}
+
+// fertilizerStats groups the fertilizations by what has been used, the
+// most recently used first.
+func (p Plant) fertilizerStats() []fertilizerStats {
+	var stats []fertilizerStats
+	index := map[string]int{}
+	for _, e := range p.History {
+		if e.Kind != eventFertilized {
+			continue
+		}
+		name := e.fertilizer()
+		if name == "" {
+			name = "unknown"
+		}
+		i, ok := index[name]
+		if !ok {
+			i = len(stats)
+			index[name] = i
+			stats = append(stats, fertilizerStats{name: name})
+		}
+		stats[i].times = append(stats[i].times, e.Time)
+	}
+	sort.SliceStable(stats, func(i, j int) bool {
+		return last(stats[i].times).After(last(stats[j].times))
+	})
+	return stats
+}
+
+// renderHistory lists the most recent events with their details.
+func (p Plant) renderHistory() string {
+	s := table.DefaultStyles()
+	s.Header = s.Header.
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderForeground(borderColor).
+		BorderBottom(true).Bold(false).Align(lipgloss.Left)
+	s.Selected = s.Cell.Padding(0)
+
+	var rows []table.Row
+	for _, e := range p.lastEvents(3) {
+		rows = append(rows, table.Row{e.Time.Format("2006-01-02"), string(e.Kind), e.details()})
+	}
+	return boxed.Render(table.New(
+		table.WithColumns([]table.Column{
+			{Title: "Recent Events", Width: 14},
+			{Title: "", Width: 11},
+			{Title: "", Width: 59},
+		}),
+		table.WithRows(rows),
+		table.WithHeight(len(rows)),
+		table.WithStyles(s),
+	).View())
 }
 
 // stripHeaderFromTable removes the Header from a table.Model.
@@ -1105,65 +1777,84 @@ func stripHeaderFromTable(table string) string {
 	return fmt.Sprintf(fmt.Sprintf("%%-%vs\n", lipgloss.Width(header)), firstLine) + rest
 }
 
-func (p Plant) nextScheduledWateringDay() string {
-	//var last time.Time
-	//if len(p.WateredAt) > 0 {
-	//last = p.WateredAt[len(p.WateredAt)-1]
-	//}
-
-	if days, ok := scheduledIn(last(p.WateredAt), p.WateringIntervals); ok {
-		return humanDaysDuration(days)
+func (p Plant) nextScheduledWateringDay(sched *Scheduler) string {
+	if w, ok := p.scheduled(sched, eventWatered); ok {
+		return w.String()
 	}
 	return "unknown"
 }
 
-func (p Plant) nextScheduledFertilizingDay() string {
-	//var last time.Time
-	//if len(p.FertilizedAt) > 0 {
-	//last = p.FertilizedAt[len(p.FertilizedAt)-1]
-	//}
-
-	if days, ok := scheduledIn(last(p.FertilizedAt), p.FertilizingIntervals); ok {
-		return humanDaysDuration(days)
+func (p Plant) nextScheduledFertilizingDay(sched *Scheduler) string {
+	if w, ok := p.scheduled(sched, eventFertilized); ok {
+		return w.String()
 	}
 	return "unknown"
 }
 
-func scheduledIn(lastEvent time.Time, intervals SeasonalIntervals) (days int, ok bool) {
-	now := time.Now()
-	d := now.YearDay()
+// window is when a task is due, in days from today. It can be done from
+// Opens on, and needs to be done by Closes.
+type window struct {
+	Opens, Closes int
+}
 
-	// TODO: this is ugly, but at least it works.
-	if d < 75 || d > 315 {
-		// is Winter
-		if intervals.Winter == 0 {
-			if d < 75 {
-				days = 75 - d
-			} else {
-				daysInYear := time.Date(now.Year(), 0, 0, 0, 0, 0, 0, time.UTC).YearDay()
-				days = (daysInYear + 75) - d
-			}
+// open returns true if the task can be done today.
+func (w window) open() bool {
+	return w.Opens <= 0
+}
 
-			// is Winter, calculate duration until summer
-		} else {
-			if lastEvent.IsZero() {
-				return 0, false
-			}
+// must returns true if the task needs to be done today or is overdue.
+func (w window) must() bool {
+	return w.Closes <= 0
+}
 
-			next := lastEvent.Add(24 * time.Hour * time.Duration(intervals.Winter))
-			days = daysFromToday(next)
-		}
-	} else {
-		if lastEvent.IsZero() {
-			return 0, false
-		}
+func (w window) String() string {
+	switch {
+	case w.Opens == w.Closes || w.must():
+		return humanDaysDuration(w.Closes)
+	case w.open():
+		return "now, by " + timeNow().AddDate(0, 0, w.Closes).Format("Jan 2")
+	default:
+		return "in " + strconv.Itoa(w.Opens) + "-" + strconv.Itoa(w.Closes) + " days"
+	}
+}
 
-		// is Summer, and interval is always set.
-		next := lastEvent.Add(24 * time.Hour * time.Duration(intervals.Summer))
-		days = daysFromToday(next)
+func (s *Scheduler) scheduledIn(lastEvent time.Time, intervals SeasonalIntervals) (w window, ok bool) {
+	return s.scheduledAt(lastEvent, intervals, timeNow())
+}
+
+// scheduledAt is scheduledIn with the intervals that apply at now, which
+// may also be in the future. The window is still in days from today.
+func (s *Scheduler) scheduledAt(lastEvent time.Time, intervals SeasonalIntervals, now time.Time) (w window, ok bool) {
+	// without any intervals, the task is not scheduled at all.
+	if intervals.unset() {
+		return window{}, false
+	}
+
+	interval := s.interval(intervals, now)
+	if interval.Max == 0 {
+		// not needed at the moment, calculate the duration until it is
+		// needed again.
+		for days := 1; days <= 366; days++ {
+			if s.interval(intervals, now.AddDate(0, 0, days)).Max != 0 {
+				day := daysFromToday(now.AddDate(0, 0, days))
+				return window{Opens: day, Closes: day}, true
+			}
+		}
+		return window{}, false
+	}
+	if lastEvent.IsZero() {
+		return window{}, false
 	}
+	return windowAfter(lastEvent, interval), true
+}
 
-	return days, true
+// windowAfter returns the window of a task that has last been done at
+// lastEvent.
+func windowAfter(lastEvent time.Time, interval dayRange) window {
+	return window{
+		Opens:  daysFromToday(lastEvent.AddDate(0, 0, interval.Min)),
+		Closes: daysFromToday(lastEvent.AddDate(0, 0, interval.Max)),
+	}
 }
 
 func humanDaysDuration(days int) string {
@@ -1197,11 +1888,27 @@ func humanDaysDuration(days int) string {
 	}
 }
 
-// will probably be slightly off in case of daylight savings time, but so what.
+// daysFromToday returns the number of calendar days from today to the
+// day of t, both in the local time zone.
 func daysFromToday(t time.Time) int {
-	today := time.Now().Truncate(24 * time.Hour)
-	days := t.Truncate(24*time.Hour).Sub(today) / (24 * time.Hour)
-	return int(days)
+	return daysBetween(timeNow(), t)
+}
+
+// daysBetween returns the number of local calendar days from the day of a
+// to the day of b. Days with a DST transition still count as one.
+func daysBetween(a, b time.Time) int {
+	ay, am, ad := a.In(time.Local).Date()
+	by, bm, bd := b.In(time.Local).Date()
+	// days in UTC are always 24 hours long.
+	from := time.Date(ay, am, ad, 0, 0, 0, 0, time.UTC)
+	to := time.Date(by, bm, bd, 0, 0, 0, 0, time.UTC)
+	return int(to.Sub(from) / (24 * time.Hour))
+}
+
+// startOfDay returns the start of the local calendar day of t.
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.In(time.Local).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.Local)
 }
 
 func formatTimeInDays(t time.Time) string {
@@ -1212,6 +1919,17 @@ func formatTimeInDays(t time.Time) string {
 	return humanDaysDuration(daysFromToday(t))
 }
 
+// since returns the times within the last days.
+func since(times []time.Time, days int) []time.Time {
+	start := timeNow().AddDate(0, 0, -days)
+	for i, t := range times {
+		if !t.Before(start) {
+			return times[i:]
+		}
+	}
+	return nil
+}
+
 // assumes the times are ordered.
 func last(times []time.Time) time.Time {
 	if len(times) == 0 {
@@ -1236,7 +1954,7 @@ func average(
 	numLastDays int,
 ) float64 {
 	dur := time.Duration(numLastDays) * time.Hour * 24
-	start := time.Now().Add(-dur)
+	start := timeNow().Add(-dur)
 
 	var intervals float64
 	var numDataPoints = 0
@@ -1,8 +1,11 @@
 package main
 
 import (
+	"strings"
 	"testing"
 	"time"
+	// the tests switch between time zones, which might not be installed.
+	_ "time/tzdata"
 )
 
 func TestFormatTimeInDays(t *testing.T) {
@@ -11,11 +14,11 @@ func TestFormatTimeInDays(t *testing.T) {
 		t      time.Time
 		format string
 	}{
-		{now, "Today"},
-		{now.Add(24 * time.Hour), "Tomorrow"},
-		{now.Add(-24 * time.Hour), "Yesterday"},
-		{now.Add(7 * -24 * time.Hour), "7 Days ago"},
-		{now.Add(7 * 24 * time.Hour), "In 7 Days"},
+		{now, "today"},
+		{now.Add(24 * time.Hour), "tomorrow"},
+		{now.Add(-24 * time.Hour), "yesterday"},
+		{now.Add(7 * -24 * time.Hour), "7 days ago"},
+		{now.Add(7 * 24 * time.Hour), "in 7 days"},
 	}
 
 	for _, tc := range testCases {
@@ -25,3 +28,192 @@ func TestFormatTimeInDays(t *testing.T) {
 		}
 	}
 }
+
+func TestScheduledAsOf(t *testing.T) {
+	t.Cleanup(func() { asOf = time.Time{} })
+	sched, err := newScheduler(defaultConfig().Seasons)
+	if err != nil {
+		t.Fatal(err)
+	}
+	intervals, err := parseSeasonalIntervals("7/14")
+	if err != nil {
+		t.Fatal(err)
+	}
+	day := func(s string) time.Time {
+		d, err := time.ParseInLocation("2006-01-02", s, time.Local)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+
+	for _, tt := range []struct {
+		watered, asOf string
+		want          window
+	}{
+		{watered: "2024-06-10", asOf: "2024-06-15", want: window{Opens: 2, Closes: 2}},
+		{watered: "2024-06-10", asOf: "2024-06-20", want: window{Opens: -3, Closes: -3}},
+		{watered: "2024-12-01", asOf: "2024-12-10", want: window{Opens: 5, Closes: 5}},
+	} {
+		asOf = day(tt.asOf)
+		p := Plant{
+			WateringIntervals: intervals,
+			History:           []CareEvent{{Kind: eventWatered, Time: day(tt.watered)}},
+		}
+		if w, ok := p.scheduled(sched, eventWatered); !ok || w != tt.want {
+			t.Fatalf("watered %s, as of %s: expected=%+v, got=%+v (%v)", tt.watered, tt.asOf, tt.want, w, ok)
+		}
+	}
+
+	asOf = day("2024-06-15") // a Saturday
+	for s, want := range map[string]string{"sat": "2024-06-15", "mon": "2024-06-17", "-1w": "2024-06-08", "3d": "2024-06-18"} {
+		got, err := parseAsOf(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Format("2006-01-02") != want {
+			t.Fatalf("%s: expected=%s, got=%s", s, want, got.Format("2006-01-02"))
+		}
+	}
+}
+
+// inLocation makes name the local time zone for the rest of the test.
+func inLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	local := time.Local
+	time.Local = loc
+	t.Cleanup(func() { time.Local = local })
+	return loc
+}
+
+func TestDaysBetween(t *testing.T) {
+	for _, tt := range []struct {
+		location string
+		a, b     string
+		days     int
+	}{
+		// the 23 hour day when DST starts.
+		{"Europe/Zurich", "2024-03-31T00:30:00+01:00", "2024-04-01T00:10:00+02:00", 1},
+		{"Europe/Zurich", "2024-03-24T21:00:00+01:00", "2024-03-31T20:00:00+02:00", 7},
+		// the 25 hour day when DST ends.
+		{"Europe/Zurich", "2024-10-27T00:30:00+02:00", "2024-10-27T23:30:00+01:00", 0},
+		{"America/New_York", "2024-11-02T23:00:00-04:00", "2024-11-04T00:30:00-05:00", 2},
+		// near midnight, the day in UTC is a different one.
+		{"Europe/Zurich", "2024-06-01T23:30:00+02:00", "2024-06-02T00:30:00+02:00", 1},
+		{"America/New_York", "2024-06-01T20:30:00-04:00", "2024-06-01T23:30:00-04:00", 0},
+		// times are compared in the local zone, whatever zone they're in.
+		{"Asia/Tokyo", "2024-06-01T16:00:00Z", "2024-06-02T08:00:00+09:00", 0},
+	} {
+		inLocation(t, tt.location)
+		a, err := time.Parse(time.RFC3339, tt.a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := time.Parse(time.RFC3339, tt.b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if days := daysBetween(a, b); days != tt.days {
+			t.Fatalf("%s: %s to %s: expected=%d, got=%d", tt.location, tt.a, tt.b, tt.days, days)
+		}
+		if sameDay(a, b) != (tt.days == 0) {
+			t.Fatalf("%s: %s and %s: not on the same day", tt.location, tt.a, tt.b)
+		}
+	}
+}
+
+func TestScheduledAcrossDST(t *testing.T) {
+	t.Cleanup(func() { asOf = time.Time{} })
+	sched, err := newScheduler(defaultConfig().Seasons)
+	if err != nil {
+		t.Fatal(err)
+	}
+	intervals, err := parseSeasonalIntervals("7/7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		location      string
+		watered, asOf string
+		opens         int
+	}{
+		{location: "Europe/Zurich", watered: "2024-03-24 21:00", asOf: "2024-03-30", opens: 1},
+		{location: "Europe/Zurich", watered: "2024-10-20 23:30", asOf: "2024-10-27", opens: 0},
+		{location: "America/New_York", watered: "2024-03-09 23:30", asOf: "2024-03-10", opens: 6},
+		{location: "America/New_York", watered: "2024-11-01 00:15", asOf: "2024-11-10", opens: -2},
+		{location: "Pacific/Auckland", watered: "2024-04-06 23:45", asOf: "2024-04-07", opens: 6},
+	} {
+		loc := inLocation(t, tt.location)
+		watered, err := time.ParseInLocation("2006-01-02 15:04", tt.watered, loc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if asOf, err = time.ParseInLocation("2006-01-02", tt.asOf, loc); err != nil {
+			t.Fatal(err)
+		}
+		p := Plant{
+			WateringIntervals: intervals,
+			History:           []CareEvent{{Kind: eventWatered, Time: watered}},
+		}
+		if w, ok := p.scheduled(sched, eventWatered); !ok || w.Opens != tt.opens {
+			t.Fatalf("%s: watered %s, as of %s: expected due in %d days, got %+v", tt.location, tt.watered, tt.asOf, tt.opens, w)
+		}
+	}
+}
+
+func TestLocalDates(t *testing.T) {
+	inLocation(t, "America/Los_Angeles")
+	d, err := parseInputDate("2024-06-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Format(time.RFC3339) != "2024-06-01T00:00:00-07:00" {
+		t.Fatalf("date not parsed as local midnight: %s", d.Format(time.RFC3339))
+	}
+
+	// 23:30 in Los Angeles is already the next day in UTC.
+	late := time.Date(2024, 6, 1, 23, 30, 0, 0, time.Local)
+	p := Plant{History: []CareEvent{{Kind: eventWatered, Time: late}}}
+	events := p.Events()
+	if len(events) != 1 || !events[0].Time.Equal(d) {
+		t.Fatalf("event not shown on its local day: %+v", events)
+	}
+}
+
+func TestParseLightLevelOutOfRange(t *testing.T) {
+	for _, s := range []string{"-1", "4"} {
+		if _, err := parseLightLevel(s); err == nil {
+			t.Fatalf("expected an error for light level %s", s)
+		}
+	}
+}
+
+func TestItemsDescribeCurrentWatering(t *testing.T) {
+	sched, err := newScheduler(defaultConfig().Seasons)
+	if err != nil {
+		t.Fatal(err)
+	}
+	intervals, err := parseSeasonalIntervals("7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &Plant{
+		Name:              "Fred",
+		WateringIntervals: intervals,
+		History:           []CareEvent{{Kind: eventWatered, Time: time.Now().AddDate(0, 0, -10)}},
+	}
+	pDB := &PlantDB{sched: sched, Plants: []*Plant{p}}
+
+	items := pDB.Items()
+	if d := items[0].(plantItem).Description(); !strings.HasSuffix(d, "must water") {
+		t.Fatalf("expected Fred to need water, got %q", d)
+	}
+	p.appendEvent(CareEvent{Kind: eventWatered, Time: time.Now()})
+	if d := items[0].(plantItem).Description(); strings.HasSuffix(d, "must water") || strings.HasSuffix(d, "can water") {
+		t.Fatalf("expected Fred not to need water after watering, got %q", d)
+	}
+}
@@ -0,0 +1,330 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+)
+
+// dbVersion is the current version of the DB format. It needs to be
+// increased with every migration that is added.
+const dbVersion = 13
+
+// migrations upgrade a DB in its generic JSON form, migrations[i] upgrades
+// it from version i to i+1. The DB is only written in the current version,
+// so every change to the format needs a migration.
+var migrations = []func(db map[string]any) error{
+	0: migrateLightLevelNames,
+	// added the archive fields.
+	1: onlyNewFields,
+	2: migratePlantIDs,
+	3: migrateEventRecords,
+	4: migrateFertilizerPerEvent,
+	5: migrateRepotSizes,
+	// added the intervals of custom tasks.
+	6: onlyNewFields,
+	// added reminders.
+	7: onlyNewFields,
+	// added four-season and monthly intervals.
+	8: onlyNewFields,
+	// intervals can be ranges, which are written as objects.
+	9: onlyNewFields,
+	// added adaptive watering.
+	10: onlyNewFields,
+	// added snoozing and skipping tasks.
+	11: onlyNewFields,
+	12: migrateLocalDates,
+}
+
+func init() {
+	if len(migrations) != dbVersion {
+		panic("number of migrations does not match dbVersion")
+	}
+}
+
+// errDBTooNew is returned when reading a DB that has been written by a
+// newer version of positive-hydration.
+type errDBTooNew struct {
+	version int
+}
+
+func (e errDBTooNew) Error() string {
+	return fmt.Sprintf("DB has been written by a newer version of positive-hydration "+
+		"(format version %d, supported up to %d), refusing to touch it", e.version, dbVersion)
+}
+
+// unmarshalDB parses the JSON of a whole DB, migrating it to the current
+// version first if necessary. It returns the version the DB had.
+func unmarshalDB(data []byte) (*PlantDB, int, error) {
+	var doc map[string]any
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return nil, 0, err
+	}
+
+	from, err := docVersion(doc)
+	if err != nil {
+		return nil, 0, err
+	}
+	if from > dbVersion {
+		return nil, from, errDBTooNew{version: from}
+	}
+	if from < dbVersion {
+		for v := from; v < dbVersion; v++ {
+			if err := migrations[v](doc); err != nil {
+				return nil, from, fmt.Errorf("could not migrate DB from version %d to %d: %w", v, v+1, err)
+			}
+		}
+		doc["version"] = dbVersion
+		if data, err = json.Marshal(doc); err != nil {
+			return nil, from, err
+		}
+	}
+
+	pDB := &PlantDB{migrated: from < dbVersion}
+	if err := json.Unmarshal(data, pDB); err != nil {
+		return nil, from, err
+	}
+	return pDB, from, nil
+}
+
+// docVersion returns the version of a DB in its generic JSON form. DBs
+// from before versioning have no version field and are version 0.
+func docVersion(doc map[string]any) (int, error) {
+	v, ok := doc["version"]
+	if !ok {
+		return 0, nil
+	}
+	n, ok := v.(json.Number)
+	if !ok {
+		return 0, fmt.Errorf("invalid DB version %v", v)
+	}
+	version, err := n.Int64()
+	if err != nil || version < 0 {
+		return 0, fmt.Errorf("invalid DB version %v", v)
+	}
+	return int(version), nil
+}
+
+// backupFile copies the file at name to a backup named after the version
+// of its content, unless such a backup exists already.
+func backupFile(name string, data []byte, version int) error {
+	backup := fmt.Sprintf("%s.v%d.bak", name, version)
+	if _, err := os.Stat(backup); err == nil {
+		return nil
+	}
+	if err := writeFileAtomic(backup, data, 0600); err != nil {
+		return fmt.Errorf("could not back up DB before migrating it: %w", err)
+	}
+	return nil
+}
+
+// plantDocs returns the plants of a DB in its generic JSON form.
+func plantDocs(db map[string]any) []map[string]any {
+	plants, _ := db["plants"].([]any)
+	docs := make([]map[string]any, 0, len(plants))
+	for _, p := range plants {
+		if doc, ok := p.(map[string]any); ok {
+			docs = append(docs, doc)
+		}
+	}
+	return docs
+}
+
+// onlyNewFields is the migration for versions that only add optional
+// fields. Bumping the version keeps older versions from silently
+// dropping them.
+func onlyNewFields(map[string]any) error {
+	return nil
+}
+
+// migrateLightLevelNames converts light levels from their 1-based index
+// in lightLevels (0 being unknown) to their names.
+func migrateLightLevelNames(db map[string]any) error {
+	for _, p := range plantDocs(db) {
+		v, ok := p["light_level"]
+		if !ok {
+			continue
+		}
+		n, ok := v.(json.Number)
+		if !ok {
+			return fmt.Errorf("invalid light level %v", v)
+		}
+		i, err := n.Int64()
+		if err != nil || i < 0 || i > int64(len(lightLevels)) {
+			return fmt.Errorf("invalid light level %v", v)
+		}
+		if i == 0 {
+			delete(p, "light_level")
+			continue
+		}
+		p["light_level"] = string(lightLevels[i-1])
+	}
+	return nil
+}
+
+// migratePlantIDs gives every plant an ID.
+func migratePlantIDs(db map[string]any) error {
+	taken := map[string]bool{}
+	for _, p := range plantDocs(db) {
+		if id, ok := p["id"].(string); ok && id != "" {
+			taken[id] = true
+		}
+	}
+	for _, p := range plantDocs(db) {
+		if id, ok := p["id"].(string); !ok || id == "" {
+			id = newPlantID(taken)
+			taken[id] = true
+			p["id"] = id
+		}
+	}
+	return nil
+}
+
+// migrateEventRecords replaces the lists of times per event kind with a
+// single list of events.
+func migrateEventRecords(db map[string]any) error {
+	// a slice, so that events at the same time always end up in the
+	// same order.
+	fields := []struct {
+		name string
+		kind eventKind
+	}{
+		{"watered_at", eventWatered},
+		{"fertilized_at", eventFertilized},
+		{"repotted_at", eventRepotted},
+	}
+	for _, p := range plantDocs(db) {
+		// the SQLite storage has the events in this form already.
+		existing, _ := p["events"].([]any)
+		var events []CareEvent
+		for _, field := range fields {
+			kind := field.kind
+			times, _ := p[field.name].([]any)
+			for _, v := range times {
+				s, _ := v.(string)
+				t, err := time.Parse(time.RFC3339Nano, s)
+				if err != nil {
+					return fmt.Errorf("invalid %s time %v", kind, v)
+				}
+				events = append(events, CareEvent{Time: t, Kind: kind})
+			}
+			delete(p, field.name)
+		}
+		sort.SliceStable(events, func(i, j int) bool {
+			return events[i].Time.Before(events[j].Time)
+		})
+		// later migrations expect the generic JSON form.
+		docs := existing
+		for _, e := range events {
+			docs = append(docs, map[string]any{
+				"time": e.Time.Format(time.RFC3339Nano),
+				"kind": string(e.Kind),
+			})
+		}
+		p["events"] = docs
+	}
+	return nil
+}
+
+// migrateFertilizerPerEvent moves the fertilizer type from the plant to
+// its fertilizations. Only the last type used was known, so all of them
+// get that one.
+func migrateFertilizerPerEvent(db map[string]any) error {
+	for _, p := range plantDocs(db) {
+		ft, _ := p["fertilizer_type"].(string)
+		delete(p, "fertilizer_type")
+		if ft == "" {
+			continue
+		}
+		events, _ := p["events"].([]any)
+		for _, v := range events {
+			if e, ok := v.(map[string]any); ok && e["kind"] == string(eventFertilized) {
+				e["fertilizer"] = ft
+			}
+		}
+	}
+	return nil
+}
+
+// migrateRepotSizes sets the pot size of the latest repotting to the pot
+// size of the plant, the sizes of earlier ones aren't known.
+func migrateRepotSizes(db map[string]any) error {
+	for _, p := range plantDocs(db) {
+		size, ok := p["pot_size"].(json.Number)
+		if !ok || size.String() == "0" {
+			continue
+		}
+		events, _ := p["events"].([]any)
+		var latest map[string]any
+		var latestTime time.Time
+		for _, v := range events {
+			e, ok := v.(map[string]any)
+			if !ok || e["kind"] != string(eventRepotted) {
+				continue
+			}
+			s, _ := e["time"].(string)
+			t, err := time.Parse(time.RFC3339Nano, s)
+			if err != nil {
+				return fmt.Errorf("invalid repotting time %v", e["time"])
+			}
+			if latest == nil || t.After(latestTime) {
+				latest, latestTime = e, t
+			}
+		}
+		if latest != nil {
+			latest["pot_size"] = size
+		}
+	}
+	return nil
+}
+
+// migrateLocalDates moves days that have been entered as dates, which
+// were stored as midnight in UTC, to midnight in the local time zone, as
+// days are local calendar days now.
+func migrateLocalDates(db map[string]any) error {
+	local := func(doc map[string]any, field string) error {
+		s, ok := doc[field].(string)
+		if !ok {
+			return nil
+		}
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return fmt.Errorf("invalid %s %v", field, doc[field])
+		}
+		if _, offset := t.Zone(); offset != 0 || t.Hour() != 0 || t.Minute() != 0 || t.Second() != 0 || t.Nanosecond() != 0 {
+			return nil
+		}
+		doc[field] = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local).Format(time.RFC3339Nano)
+		return nil
+	}
+	for _, p := range plantDocs(db) {
+		if err := local(p, "archived_at"); err != nil {
+			return err
+		}
+		events, _ := p["events"].([]any)
+		for _, v := range events {
+			if e, ok := v.(map[string]any); ok {
+				if err := local(e, "time"); err != nil {
+					return err
+				}
+			}
+		}
+		reminders, _ := p["reminders"].([]any)
+		for _, v := range reminders {
+			if r, ok := v.(map[string]any); ok {
+				if err := local(r, "due"); err != nil {
+					return err
+				}
+				if err := local(r, "done_at"); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
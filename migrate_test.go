@@ -0,0 +1,163 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestUnmarshalDBMigrations(t *testing.T) {
+	testCases := []struct {
+		data        string
+		fromVersion int
+		lightLevel  LightLevel
+	}{
+		{`{"plants":[{"name":"a","light_level":4}]}`, 0, "semi-shaded / shaded"},
+		{`{"plants":[{"name":"a","light_level":0}]}`, 0, ""},
+		{`{"plants":[{"name":"a"}]}`, 0, ""},
+		{`{"version":1,"plants":[{"name":"a","light_level":"direct sunlight"}]}`, 1, "direct sunlight"},
+	}
+
+	for _, tc := range testCases {
+		pDB, from, err := unmarshalDB([]byte(tc.data))
+		if err != nil {
+			t.Fatalf("could not unmarshal %s: %v", tc.data, err)
+		}
+		if from != tc.fromVersion {
+			t.Fatalf("wrong version for %s. expected=%v, got=%v", tc.data, tc.fromVersion, from)
+		}
+		if pDB.Version != dbVersion {
+			t.Fatalf("DB has not been migrated. expected=%v, got=%v", dbVersion, pDB.Version)
+		}
+		if got := pDB.Plants[0].LightLevel; got != tc.lightLevel {
+			t.Fatalf("light level not migrated. expected=%q, got=%q", tc.lightLevel, got)
+		}
+	}
+}
+
+func TestUnmarshalDBTooNew(t *testing.T) {
+	_, _, err := unmarshalDB([]byte(`{"version":9999,"plants":[]}`))
+	var tooNew errDBTooNew
+	if !errors.As(err, &tooNew) {
+		t.Fatalf("expected errDBTooNew, got %v", err)
+	}
+}
+
+func TestUnmarshalDBPlantIDs(t *testing.T) {
+	pDB, _, err := unmarshalDB([]byte(`{"version":2,"plants":[{"name":"a"},{"name":"a"},{"name":"b","id":"0000beef"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := map[string]bool{}
+	for _, p := range pDB.Plants {
+		if p.ID == "" || ids[p.ID] {
+			t.Fatalf("plant %s has no unique ID: %q", p.Name, p.ID)
+		}
+		ids[p.ID] = true
+	}
+	if pDB.Plants[2].ID != "0000beef" {
+		t.Fatalf("existing ID has been changed to %q", pDB.Plants[2].ID)
+	}
+}
+
+func TestUnmarshalDBEventRecords(t *testing.T) {
+	pDB, _, err := unmarshalDB([]byte(`{"version":3,"plants":[{"name":"a",` +
+		`"watered_at":["2023-01-03T00:00:00Z","2023-01-01T00:00:00Z"],` +
+		`"fertilized_at":["2023-01-02T00:00:00Z"],"repotted_at":null}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var kinds []eventKind
+	for _, e := range pDB.Plants[0].History {
+		kinds = append(kinds, e.Kind)
+	}
+	expected := []eventKind{eventWatered, eventFertilized, eventWatered}
+	if !reflect.DeepEqual(kinds, expected) {
+		t.Fatalf("events not migrated. expected=%v, got=%v", expected, kinds)
+	}
+
+	// events at the same time keep the order of their kinds.
+	for i := 0; i < 10; i++ {
+		pDB, _, err := unmarshalDB([]byte(`{"version":3,"plants":[{"name":"a",` +
+			`"repotted_at":["2023-01-01T00:00:00Z"],"fertilized_at":["2023-01-01T00:00:00Z"],"watered_at":["2023-01-01T00:00:00Z"]}]}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		kinds = nil
+		for _, e := range pDB.Plants[0].History {
+			kinds = append(kinds, e.Kind)
+		}
+		expected := []eventKind{eventWatered, eventFertilized, eventRepotted}
+		if !reflect.DeepEqual(kinds, expected) {
+			t.Fatalf("events at the same time in a different order. expected=%v, got=%v", expected, kinds)
+		}
+	}
+}
+
+func TestUnmarshalDBFertilizerPerEvent(t *testing.T) {
+	pDB, _, err := unmarshalDB([]byte(`{"version":4,"plants":[{"name":"a","fertilizer_type":"liquid",` +
+		`"events":[{"time":"2023-01-01T00:00:00Z","kind":"watered"},{"time":"2023-01-02T00:00:00Z","kind":"fertilized"}]}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := pDB.Plants[0].History
+	if events[0].Fertilizer != "" || events[1].Fertilizer != LiquidFertilizer {
+		t.Fatalf("fertilizer type not migrated: %+v", events)
+	}
+}
+
+func TestUnmarshalDBRepotSizes(t *testing.T) {
+	pDB, _, err := unmarshalDB([]byte(`{"version":5,"plants":[{"name":"a","pot_size":14,` +
+		`"events":[{"time":"2023-03-01T00:00:00Z","kind":"repotted"},{"time":"2022-01-01T00:00:00Z","kind":"repotted"}]}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := pDB.Plants[0].History
+	if events[0].PotSize != 14 || events[1].PotSize != 0 {
+		t.Fatalf("pot size not set on the latest repotting: %+v", events)
+	}
+}
+
+func TestUnmarshalDBLocalDates(t *testing.T) {
+	inLocation(t, "America/New_York")
+	pDB, _, err := unmarshalDB([]byte(`{"version":12,"plants":[{"name":"a",` +
+		`"events":[{"time":"2023-01-01T00:00:00Z","kind":"watered"},{"time":"2023-01-02T18:30:00+01:00","kind":"watered"}],` +
+		`"reminders":[{"due":"2023-02-01T00:00:00Z","text":"check roots"}]}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := pDB.Plants[0]
+	expected := []string{"2023-01-01T00:00:00-05:00", "2023-01-02T18:30:00+01:00"}
+	for i, e := range p.History {
+		if e.Time.Format(time.RFC3339) != expected[i] {
+			t.Fatalf("event %d: expected=%s, got=%s", i, expected[i], e.Time.Format(time.RFC3339))
+		}
+	}
+	if due := p.Reminders[0].Due.Format(time.RFC3339); due != "2023-02-01T00:00:00-05:00" {
+		t.Fatalf("reminder not moved to the local day: %s", due)
+	}
+}
+
+func TestMigratedDBIsWritten(t *testing.T) {
+	location := filepath.Join(t.TempDir(), "plants.json")
+	if err := os.WriteFile(location, []byte(`{"plants":[{"name":"a"}]}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for i := 0; i < 2; i++ {
+		pDB, err := openDB(location, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, pDB.Plants[0].ID)
+		if err := pDB.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if ids[0] == "" || ids[0] != ids[1] {
+		t.Fatalf("plant IDs of a migrated DB are not stable: %v", ids)
+	}
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Reminder is a one-off follow-up, e.g. "check roots" in two weeks.
+type Reminder struct {
+	Due  time.Time `json:"due"`
+	Text string    `json:"text"`
+	// DoneAt is set once the reminder has been completed.
+	DoneAt *time.Time `json:"done_at,omitempty"`
+}
+
+func (r Reminder) done() bool {
+	return r.DoneAt != nil
+}
+
+// parseReminderDate accepts a date like parseRelativeDate, but not before
+// today.
+func parseReminderDate(s string) (time.Time, error) {
+	t, err := parseRelativeDate(s)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if daysFromToday(t) < 0 {
+		return time.Time{}, fmt.Errorf("day is in the past")
+	}
+	return t, nil
+}
+
+// parseRelativeDate accepts a date like parseInputDate, but also in the
+// future, or a duration from today in days, weeks or months, e.g. 2w.
+func parseRelativeDate(s string) (time.Time, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "in ")
+	if n := len(s); n > 1 {
+		if count, err := strconv.Atoi(s[:n-1]); err == nil {
+			today := startOfDay(timeNow())
+			switch s[n-1] {
+			case 'd':
+				return today.AddDate(0, 0, count), nil
+			case 'w':
+				return today.AddDate(0, 0, 7*count), nil
+			case 'm':
+				return today.AddDate(0, count, 0), nil
+			}
+		}
+	}
+	t, err := time.ParseInLocation("2006-01-02", s, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected a date (YYYY-MM-DD) or a duration like 10d, 2w or 3m")
+	}
+	return t, nil
+}
+
+// addReminder adds a reminder, keeping them ordered by due date.
+func (p *Plant) addReminder(due time.Time, text string) {
+	p.Reminders = append(p.Reminders, Reminder{Due: due, Text: text})
+	sort.SliceStable(p.Reminders, func(i, j int) bool {
+		return p.Reminders[i].Due.Before(p.Reminders[j].Due)
+	})
+}
+
+// openReminders returns the indices of the reminders that haven't been
+// completed yet, the earliest first.
+func (p Plant) openReminders() []int {
+	var open []int
+	for i, r := range p.Reminders {
+		if !r.done() {
+			open = append(open, i)
+		}
+	}
+	return open
+}
+
+// nextReminder returns the index of the earliest open reminder containing
+// text, or -1.
+func (p Plant) nextReminder(text string) int {
+	for _, i := range p.openReminders() {
+		if strings.Contains(strings.ToLower(p.Reminders[i].Text), strings.ToLower(text)) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (p *Plant) completeReminder(i int, at time.Time) {
+	p.Reminders[i].DoneAt = &at
+}
+
+// String returns the text and when the reminder is due.
+func (r Reminder) String() string {
+	return r.Text + " (" + formatTimeInDays(r.Due) + ")"
+}
+
+func newReminderPrompt(plant *Plant) *inputPrompt {
+	// not validated while typing, as e.g. "2" is not valid on its own.
+	when := newTextInput("When", "YYYY-MM-DD, 10d, 2w, 3m")
+	when.Focus()
+	when.PromptStyle = focusedStyle
+	when.TextStyle = focusedStyle
+	return &inputPrompt{
+		inputs: []textinput.Model{when, newTextInput("Reminder", "check roots")},
+		title:  "Remind about " + plant.Name,
+		confirmAction: func(ip *inputPrompt) (tea.Model, error) {
+			due, err := parseReminderDate(ip.inputs[0].Value())
+			if err != nil {
+				return nil, fmt.Errorf("invalid date: %v", err)
+			}
+			text := ip.inputs[1].Value()
+			if text == "" {
+				return nil, fmt.Errorf("reminder cannot be empty!")
+			}
+			plant.addReminder(due, text)
+			return nil, nil
+		},
+	}
+}
+
+// newCompleteReminderPrompt asks to complete the earliest open reminder
+// of the plant, it returns nil if there is none.
+func newCompleteReminderPrompt(plant *Plant) *confirmPrompt {
+	i := plant.nextReminder("")
+	if i < 0 {
+		return nil
+	}
+	return &confirmPrompt{
+		title:    "Complete Reminder",
+		question: fmt.Sprintf("Mark %q of %s as done?", plant.Reminders[i].String(), plant.Name),
+		confirm:  func() { plant.completeReminder(i, timeNow()) },
+	}
+}
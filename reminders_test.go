@@ -0,0 +1,108 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseReminderDate(t *testing.T) {
+	t.Cleanup(func() { asOf = time.Time{} })
+	asOf = time.Date(2024, 6, 15, 0, 0, 0, 0, time.Local)
+
+	for s, want := range map[string]string{
+		"2024-06-20": "2024-06-20",
+		"2024-06-15": "2024-06-15",
+		"0d":         "2024-06-15",
+		"3d":         "2024-06-18",
+		"in 2w":      "2024-06-29",
+		"1m":         "2024-07-15",
+	} {
+		got, err := parseReminderDate(s)
+		if err != nil {
+			t.Fatalf("%s: %v", s, err)
+		}
+		if got.Format("2006-01-02") != want {
+			t.Fatalf("%s: expected=%s, got=%s", s, want, got.Format("2006-01-02"))
+		}
+	}
+	for _, s := range []string{"", "soon", "2w3d", "2024-06-14", "2020-01-01", "-3d", "-1w"} {
+		if got, err := parseReminderDate(s); err == nil {
+			t.Fatalf("expected an error for %q, got %s", s, got)
+		}
+	}
+	// the day to act on may be in the past.
+	if got, err := parseRelativeDate("-3d"); err != nil || got.Format("2006-01-02") != "2024-06-12" {
+		t.Fatalf("expected 2024-06-12, got %s (%v)", got, err)
+	}
+}
+
+func TestNextReminder(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2024, 6, d, 0, 0, 0, 0, time.Local)
+	}
+	var p Plant
+	p.addReminder(day(12), "check roots")
+	p.addReminder(day(10), "Check roots")
+	p.addReminder(day(11), "mist")
+	p.completeReminder(p.nextReminder("roots"), day(10))
+
+	for text, want := range map[string]string{"": "mist", "ROOTS": "check roots", "mist": "mist", "prune": ""} {
+		i := p.nextReminder(text)
+		var got string
+		if i >= 0 {
+			got = p.Reminders[i].Text
+		}
+		if got != want {
+			t.Fatalf("%q: expected=%q, got=%q", text, want, got)
+		}
+	}
+	if open := p.openReminders(); len(open) != 2 {
+		t.Fatalf("expected 2 open reminders, got %v", open)
+	}
+}
+
+func TestReminderCommands(t *testing.T) {
+	t.Cleanup(func() { asOf = time.Time{} })
+	asOf = time.Date(2024, 6, 15, 0, 0, 0, 0, time.Local)
+	location := filepath.Join(t.TempDir(), "plants.json")
+	pDB, err := openDB(location, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pDB.Plants = []*Plant{{ID: "0000fred", Name: "Fred"}}
+	if err := pDB.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		args []string
+		code int
+	}{
+		{args: []string{"remind", "Fred", "-3d", "mist"}, code: 1},
+		{args: []string{"remind", "Fred", "2w", "check", "roots"}, code: 0},
+		{args: []string{"remind", "Fred", "3d", "mist"}, code: 0},
+		{args: []string{"complete", "Fred", "roots"}, code: 0},
+		{args: []string{"complete", "Fred", "roots"}, code: 1},
+	} {
+		if pDB, err = openDB(location, nil); err != nil {
+			t.Fatal(err)
+		}
+		if code := runCommand(pDB, tt.args); code != tt.code {
+			t.Fatalf("%v: expected exit code %d, got %d", tt.args, tt.code, code)
+		}
+	}
+
+	pDB, err = openDB(location, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pDB.Close()
+	r := pDB.Plants[0].Reminders
+	if len(r) != 2 || r[0].Text != "mist" || r[0].done() || r[1].Text != "check roots" || !r[1].done() {
+		t.Fatalf("wrong reminders: %+v", r)
+	}
+	if got := r[1].DoneAt.Format("2006-01-02"); got != "2024-06-15" {
+		t.Fatalf("expected the reminder to be completed as of 2024-06-15, got %s", got)
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// appendEvent adds e to the history and returns it. Repottings get the
+// pot size they came from, and the latest one sets the plant's pot size.
+func (p *Plant) appendEvent(e CareEvent) CareEvent {
+	if e.Kind == eventRepotted {
+		prev, hasPrev := p.repotBefore(e.Time)
+		latest := p.repotAfter(e.Time) < 0
+		switch {
+		case hasPrev && prev.PotSize != 0:
+			e.FromPotSize = prev.PotSize
+		case latest:
+			e.FromPotSize = p.PotSize
+		}
+		if latest && e.PotSize != 0 {
+			p.PotSize = e.PotSize
+		}
+	}
+	p.History = append(p.History, e)
+	sortEvents(p.History)
+	return e
+}
+
+// repotBefore returns the last repotting before t.
+func (p Plant) repotBefore(t time.Time) (CareEvent, bool) {
+	for i := len(p.History) - 1; i >= 0; i-- {
+		if e := p.History[i]; e.Kind == eventRepotted && e.Time.Before(t) {
+			return e, true
+		}
+	}
+	return CareEvent{}, false
+}
+
+// repotAfter returns the index of the first repotting after t, or -1.
+func (p Plant) repotAfter(t time.Time) int {
+	for i, e := range p.History {
+		if e.Kind == eventRepotted && e.Time.After(t) {
+			return i
+		}
+	}
+	return -1
+}
+
+// renderRepotting shows all repottings with the growth since the one
+// before.
+func (p Plant) renderRepotting() string {
+	s := table.DefaultStyles()
+	s.Header = s.Header.
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderForeground(borderColor).
+		BorderBottom(true).Bold(false).Align(lipgloss.Left)
+	s.Selected = s.Cell.Padding(0)
+
+	var (
+		rows []table.Row
+		prev *CareEvent
+	)
+	for i, e := range p.History {
+		if e.Kind != eventRepotted {
+			continue
+		}
+		rows = append(rows, table.Row{
+			e.Time.Format("2006-01-02"), formatPotSizes(e.FromPotSize, e.PotSize),
+			e.PotMaterial, e.SoilMix, formatGrowth(prev, e),
+		})
+		prev = &p.History[i]
+	}
+	// most recent first, like the other events.
+	for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+		rows[i], rows[j] = rows[j], rows[i]
+	}
+
+	return boxed.Render(table.New(
+		table.WithColumns([]table.Column{
+			{Title: "Repotting", Width: 14},
+			{Title: "Pot Size", Width: 12},
+			{Title: "Material", Width: 14},
+			{Title: "Soil Mix", Width: 20},
+			{Title: "Growth", Width: 20},
+		}),
+		table.WithRows(rows),
+		table.WithHeight(len(rows)),
+		table.WithStyles(s),
+	).View())
+}
+
+func formatPotSizes(from, to int) string {
+	size := func(s int) string {
+		if s == 0 {
+			return "?"
+		}
+		return strconv.Itoa(s)
+	}
+	if to == 0 && from == 0 {
+		return "unknown"
+	}
+	if from == 0 || from == to {
+		return size(to) + "cm"
+	}
+	return size(from) + " → " + size(to) + "cm"
+}
+
+// formatGrowth returns how much the pot has grown with the repotting e,
+// and in how much time if there has been a repotting before.
+func formatGrowth(prev *CareEvent, e CareEvent) string {
+	from := e.FromPotSize
+	if from == 0 && prev != nil {
+		from = prev.PotSize
+	}
+	if from == 0 || e.PotSize == 0 {
+		return ""
+	}
+
+	growth := strconv.Itoa(e.PotSize-from) + "cm"
+	if e.PotSize > from {
+		growth = "+" + growth
+	}
+	if prev == nil {
+		return growth
+	}
+	months := int(e.Time.Sub(prev.Time).Hours() / 24 / 30)
+	if months < 1 {
+		return growth + " in <1 month"
+	}
+	if months == 1 {
+		return growth + " in 1 month"
+	}
+	return growth + " in " + strconv.Itoa(months) + " months"
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Seasons defines when summer and winter start. They are either given
+// explicitly, or computed from the latitude. Without either, the default
+// seasons of the hemisphere are used.
+type Seasons struct {
+	// Hemisphere is "north" or "south", it only changes the defaults.
+	Hemisphere  string  `toml:"hemisphere"`
+	SummerStart yearDay `toml:"summer_start"`
+	WinterStart yearDay `toml:"winter_start"`
+	// Latitude computes the seasons offline: it's summer while the days
+	// are at least DayLength hours long.
+	Latitude  *float64 `toml:"latitude"`
+	DayLength float64  `toml:"day_length"`
+}
+
+// default season starts of the northern hemisphere, the south is shifted
+// by half a year. Summer starts on Mar 16 and winter on Nov 12, as it
+// always has.
+const (
+	defaultSummerStart = 75
+	defaultWinterStart = 316
+	daysInYear         = 365
+)
+
+// yearDay is a day of the year, configured either as a number or as a
+// date in the form MM-DD. Dates are counted in a year without Feb 29.
+type yearDay int
+
+func (d *yearDay) UnmarshalTOML(v any) error {
+	switch v := v.(type) {
+	case int64:
+		*d = yearDay(v)
+	case string:
+		t, err := time.Parse("2006-01-02", "2001-"+v)
+		if err != nil {
+			return fmt.Errorf("expected a day of the year or a date as MM-DD, got %q", v)
+		}
+		*d = yearDay(t.YearDay())
+	default:
+		return fmt.Errorf("expected a day of the year or a date as MM-DD, got %v", v)
+	}
+	return nil
+}
+
+// date returns the day formatted like "Mar 16".
+func (d yearDay) date() string {
+	return time.Date(2001, 1, int(d), 0, 0, 0, 0, time.UTC).Format("Jan 2")
+}
+
+// bounds returns the first days of summer and winter. Summer starts after
+// winter if the seasons are across the new year.
+func (s Seasons) bounds() (summer, winter yearDay, err error) {
+	if s.Latitude != nil {
+		return dayLengthBounds(*s.Latitude, s.DayLength)
+	}
+
+	summer, winter = defaultSummerStart, defaultWinterStart
+	switch s.Hemisphere {
+	case "", "north":
+	case "south":
+		summer, winter = shiftDay(summer, daysInYear/2), shiftDay(winter, daysInYear/2)
+	default:
+		return 0, 0, fmt.Errorf("hemisphere needs to be north or south, got %q", s.Hemisphere)
+	}
+	if s.SummerStart != 0 {
+		summer = s.SummerStart
+	}
+	if s.WinterStart != 0 {
+		winter = s.WinterStart
+	}
+	for _, d := range []yearDay{summer, winter} {
+		if d < 1 || d > 366 {
+			return 0, 0, fmt.Errorf("summer_start and winter_start need to be days of the year")
+		}
+	}
+	if summer == winter {
+		return 0, 0, fmt.Errorf("summer and winter can't start on the same day")
+	}
+	return summer, winter, nil
+}
+
+func shiftDay(d yearDay, days int) yearDay {
+	return yearDay((int(d)-1+days)%daysInYear + 1)
+}
+
+// dayLengthBounds returns the days on which the day length at the given
+// latitude starts to be at least hours long, and when it stops to be.
+func dayLengthBounds(latitude, hours float64) (summer, winter yearDay, err error) {
+	if latitude < -90 || latitude > 90 {
+		return 0, 0, fmt.Errorf("latitude needs to be between -90 and 90")
+	}
+	long := func(d int) bool { return dayLength(latitude, d) >= hours }
+	for d := 1; d <= daysInYear; d++ {
+		prev := shiftDay(yearDay(d), daysInYear-1)
+		switch {
+		case long(d) && !long(int(prev)):
+			summer = yearDay(d)
+		case !long(d) && long(int(prev)):
+			winter = yearDay(d)
+		}
+	}
+	if summer == 0 || winter == 0 {
+		return 0, 0, fmt.Errorf("the days at latitude %v are never or always at least %vh long", latitude, hours)
+	}
+	return summer, winter, nil
+}
+
+// dayLength approximates the hours between sunrise and sunset on the
+// given day of the year, from the declination of the sun.
+func dayLength(latitude float64, day int) float64 {
+	declination := -23.44 * math.Cos(2*math.Pi/daysInYear*float64(day+10))
+	x := -math.Tan(latitude*math.Pi/180) * math.Tan(declination*math.Pi/180)
+	// polar day and night.
+	x = math.Max(-1, math.Min(1, x))
+	return 24 / math.Pi * math.Acos(x)
+}
+
+// Scheduler calculates when plants need care.
+type Scheduler struct {
+	summerStart, winterStart yearDay
+	// checkPostpone is how many days watering is postponed by a check.
+	checkPostpone int
+	// wateringDays are the preferred weekdays for watering, see aligned.
+	wateringDays []time.Weekday
+}
+
+func newScheduler(s Seasons) (*Scheduler, error) {
+	summer, winter, err := s.bounds()
+	if err != nil {
+		return nil, err
+	}
+	return &Scheduler{summerStart: summer, winterStart: winter}, nil
+}
+
+type season int
+
+const (
+	winter season = iota
+	spring
+	summer
+	autumn
+)
+
+var seasonNames = [...]string{"Winter", "Spring", "Summer", "Autumn"}
+
+// seasonAt returns the season on the day of t. Spring and autumn are the
+// first and last quarter of the time from summerStart to winterStart, so
+// that profiles with two seasons use their summer interval for them.
+func (s *Scheduler) seasonAt(t time.Time) season {
+	d := dayOfYear(t)
+	start, end := int(s.summerStart), int(s.winterStart)
+	if end < start {
+		// summer is across the new year.
+		end += daysInYear
+		if d < start {
+			d += daysInYear
+		}
+	}
+	quarter := (end - start) / 4
+	switch {
+	case d < start || d >= end:
+		return winter
+	case d < start+quarter:
+		return spring
+	case d >= end-quarter:
+		return autumn
+	default:
+		return summer
+	}
+}
+
+// dayOfYear returns the local day of the year of t, counted like a
+// yearDay in a year without Feb 29, which is the same day as Mar 1.
+func dayOfYear(t time.Time) int {
+	t = t.In(time.Local)
+	d := t.YearDay()
+	if y := t.Year(); t.Month() > time.February && (y%4 == 0 && y%100 != 0 || y%400 == 0) {
+		d--
+	}
+	return d
+}
+
+func (s *Scheduler) isWinter(t time.Time) bool {
+	return s.seasonAt(t) == winter
+}
+
+// interval returns the interval of si on the day of t.
+func (s *Scheduler) interval(si SeasonalIntervals, t time.Time) dayRange {
+	if len(si.Months) == 12 {
+		return si.Months[t.In(time.Local).Month()-1]
+	}
+	switch s.seasonAt(t) {
+	case spring:
+		return si.spring()
+	case autumn:
+		return si.autumn()
+	case winter:
+		return si.Winter
+	default:
+		return si.Summer
+	}
+}
+
+// intervalName returns the name of the interval of si that applies on
+// the day of t, e.g. the season or month.
+func (s *Scheduler) intervalName(si SeasonalIntervals, t time.Time) string {
+	season := s.seasonAt(t)
+	switch {
+	case len(si.Months) == 12:
+		return t.In(time.Local).Month().String()
+	case si.Spring == nil && si.Autumn == nil && season != winter:
+		return seasonNames[summer]
+	default:
+		return seasonNames[season]
+	}
+}
+
+// season describes the current season and when the next one starts.
+func (s *Scheduler) season(now time.Time) string {
+	current := s.seasonAt(now)
+	days := 1
+	for ; days <= 366 && s.seasonAt(now.AddDate(0, 0, days)) == current; days++ {
+	}
+	next := now.AddDate(0, 0, days)
+	return seasonNames[current] + ", " + strings.ToLower(seasonNames[s.seasonAt(next)]) +
+		" starts on " + next.Format("Jan 2")
+}
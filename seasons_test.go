@@ -0,0 +1,121 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+func TestSeasonsBounds(t *testing.T) {
+	for _, tt := range []struct {
+		name           string
+		config         string
+		summer, winter string
+	}{
+		{name: "defaults", summer: "Mar 16", winter: "Nov 12"},
+		{name: "south", config: `hemisphere = "south"`, summer: "Sep 14", winter: "May 13"},
+		{name: "dates", config: "summer_start = \"04-01\"\nwinter_start = 288", summer: "Apr 1", winter: "Oct 15"},
+		{name: "latitude north", config: "latitude = 47.4\nday_length = 12", summer: "Mar 23", winter: "Sep 21"},
+		{name: "latitude south", config: "latitude = -33.9\nday_length = 12", summer: "Sep 21", winter: "Mar 23"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			s := defaultConfig().Seasons
+			if _, err := toml.Decode(tt.config, &s); err != nil {
+				t.Fatal(err)
+			}
+			summer, winter, err := s.bounds()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if summer.date() != tt.summer || winter.date() != tt.winter {
+				t.Fatalf("wrong seasons. expected=%v - %v, got=%v - %v", tt.summer, tt.winter, summer.date(), winter.date())
+			}
+		})
+	}
+
+	equator := 0.0
+	if _, _, err := (Seasons{Latitude: &equator, DayLength: 13}).bounds(); err == nil {
+		t.Fatal("expected an error for days that are never long enough")
+	}
+}
+
+func TestSchedulerIsWinter(t *testing.T) {
+	north, _ := newScheduler(Seasons{})
+	south, _ := newScheduler(Seasons{Hemisphere: "south"})
+	for _, tt := range []struct {
+		date         string
+		north, south bool
+	}{
+		{date: "2023-01-15", north: true, south: false},
+		{date: "2023-07-15", north: false, south: true},
+		// the first and last days of summer.
+		{date: "2023-03-15", north: true, south: false},
+		{date: "2023-03-16", north: false, south: false},
+		{date: "2023-11-11", north: false, south: false},
+		{date: "2023-11-12", north: true, south: false},
+		// leap years have the same seasons.
+		{date: "2024-03-15", north: true, south: false},
+		{date: "2024-03-16", north: false, south: false},
+		{date: "2024-11-11", north: false, south: false},
+		{date: "2024-11-12", north: true, south: false},
+	} {
+		// the day counts in the local time zone, no matter its offset.
+		for _, location := range []string{"UTC", "America/Los_Angeles", "Pacific/Auckland"} {
+			t.Run(location, func(t *testing.T) {
+				inLocation(t, location)
+				d, _ := time.ParseInLocation("2006-01-02", tt.date, time.Local)
+				if north.isWinter(d) != tt.north || south.isWinter(d) != tt.south {
+					t.Errorf("wrong season on %v. expected north=%v south=%v", tt.date, tt.north, tt.south)
+				}
+			})
+		}
+	}
+}
+
+func TestSeasonalIntervals(t *testing.T) {
+	sched, _ := newScheduler(Seasons{})
+	testCases := []struct {
+		input, format string
+		// intervals on Jan 15, Apr 1, Jul 15 and Oct 15.
+		intervals [4]int
+	}{
+		{"7", "7/7", [4]int{7, 7, 7, 7}},
+		{"7/-", "7/-", [4]int{0, 7, 7, 7}},
+		{"10/7/14/-", "10/7/14/-", [4]int{0, 10, 7, 14}},
+		{"30/30/20/14/10/7/7/7/10/14/20/-", "30/30/20/14/10/7/7/7/10/14/20/-", [4]int{30, 14, 7, 14}},
+	}
+
+	for _, tc := range testCases {
+		si, err := parseSeasonalIntervals(tc.input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if si.String() != tc.format {
+			t.Fatalf("formatted string not correct. expected=%q, got=%q", tc.format, si.String())
+		}
+		for i, date := range []string{"2023-01-15", "2023-04-01", "2023-07-15", "2023-10-15"} {
+			d, _ := time.ParseInLocation("2006-01-02", date, time.Local)
+			if got := sched.interval(si, d).Max; got != tc.intervals[i] {
+				t.Fatalf("wrong interval of %q on %v. expected=%d, got=%d", tc.input, date, tc.intervals[i], got)
+			}
+		}
+	}
+
+	if _, err := parseSeasonalIntervals("7/7/7"); err == nil {
+		t.Fatal("expected an error for three intervals")
+	}
+
+	si, err := parseSeasonalIntervals("7-10/2-3w")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if si.Summer != (dayRange{7, 10}) || si.Winter != (dayRange{14, 21}) || si.String() != "7-10/14-21" {
+		t.Fatalf("wrong ranges. got=%+v (%v)", si, si)
+	}
+	for _, invalid := range []string{"10-7", "7-", "-7", "2x"} {
+		if _, err := parseSeasonalIntervals(invalid); err == nil {
+			t.Fatalf("expected an error for %q", invalid)
+		}
+	}
+}
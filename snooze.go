@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// taskName returns how a task is called, e.g. "watering" for the
+// watered events.
+func taskName(kind eventKind) string {
+	switch kind {
+	case eventWatered:
+		return "watering"
+	case eventFertilized:
+		return "fertilizing"
+	case eventRepotted:
+		return "repotting"
+	}
+	return string(kind)
+}
+
+// parseTask returns the kind of the scheduled task s, which is either
+// watering, fertilizing or a custom task. Watering and fertilizing may
+// also be given as e.g. "water" or "watered".
+func parseTask(s string) (eventKind, error) {
+	switch strings.ToLower(s) {
+	case "water", "watering", "watered":
+		return eventWatered, nil
+	case "fertilize", "fertilizing", "fertilized":
+		return eventFertilized, nil
+	}
+	if t, ok := lookupTask(s); ok {
+		return t.kind, nil
+	}
+	return "", fmt.Errorf("unknown task %q, expected watering, fertilizing or a custom task", s)
+}
+
+// deferrals returns since when the task of the given kind is scheduled
+// and the snoozes of it since then, oldest first. That's when it has last
+// been done, or when the occurrence that was pending at the last skip
+// since then was due, so that skipping doesn't bring the next one
+// forward. An occurrence that was already due when it was skipped counts
+// from the day of the skip.
+func (p Plant) deferrals(sched *Scheduler, kind eventKind) (time.Time, []CareEvent) {
+	done := last(p.times(kind))
+	from := done
+	var snoozes []CareEvent
+	for _, e := range p.History {
+		if e.Task != kind || !e.Time.After(done) {
+			continue
+		}
+		switch e.Kind {
+		case eventSnoozed:
+			snoozes = append(snoozes, e)
+		case eventSkipped:
+			w, ok := p.snoozedFrom(sched, kind, from, snoozes)
+			from = e.Time
+			if due := timeNow().AddDate(0, 0, w.Opens); ok && daysBetween(e.Time, due) > 0 {
+				from = due
+			}
+			snoozes = nil
+		}
+	}
+	return from, snoozes
+}
+
+// snoozedFrom returns the window of the task as if it had last been done
+// at from, pushed back by the snoozes.
+func (p Plant) snoozedFrom(sched *Scheduler, kind eventKind, from time.Time, snoozes []CareEvent) (window, bool) {
+	w, ok := p.scheduledFrom(sched, kind, from)
+	if ok {
+		for _, e := range snoozes {
+			w = w.snoozed(e.Time, e.Days)
+		}
+	}
+	return w, ok
+}
+
+// snoozed returns w pushed back by days from the day it has been snoozed
+// on. A window that has opened already by then opens on that day.
+func (w window) snoozed(on time.Time, days int) window {
+	day := daysFromToday(on)
+	if w.Opens < day {
+		w.Opens = day
+	}
+	if w.Closes < day {
+		w.Closes = day
+	}
+	w.Opens += days
+	w.Closes += days
+	return w
+}
+
+// newDeferPrompt asks which task of the plant to snooze or skip, kind is
+// either eventSnoozed or eventSkipped.
+func newDeferPrompt(pDB *PlantDB, plant *Plant, kind eventKind) *inputPrompt {
+	// not validated while typing, as e.g. "wat" is not valid on its own.
+	task := newTextInput("Task", "watering")
+	task.Focus()
+	task.PromptStyle = focusedStyle
+	task.TextStyle = focusedStyle
+	inputs := []textinput.Model{task}
+	title := "Skip Next Occurrence"
+	if kind == eventSnoozed {
+		inputs = append(inputs, newIntInput("Days", "1"))
+		title = "Snooze"
+	}
+	return &inputPrompt{
+		inputs: inputs,
+		title:  title + " for " + plant.Name,
+		confirmAction: func(ip *inputPrompt) (tea.Model, error) {
+			e := pDB.newEvent(kind, timeNow())
+			task := ip.inputs[0].Value()
+			if task == "" {
+				task = "watering"
+			}
+			var err error
+			if e.Task, err = parseTask(task); err != nil {
+				return nil, err
+			}
+			if kind == eventSnoozed {
+				e.Days = 1
+				if days := ip.inputs[1].Value(); days != "" {
+					if e.Days, err = strconv.Atoi(days); err != nil || e.Days < 1 {
+						return nil, fmt.Errorf("days need to be a positive number")
+					}
+				}
+			}
+			plant.appendEvent(e)
+			return nil, nil
+		},
+	}
+}
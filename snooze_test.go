@@ -0,0 +1,171 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDeferredSchedule(t *testing.T) {
+	t.Cleanup(func() { asOf = time.Time{} })
+	sched, err := newScheduler(defaultConfig().Seasons)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// all days are counted from Jun 1, which is today + today.
+	const today = 20
+	base := time.Date(2024, 6, 1, 12, 0, 0, 0, time.Local)
+	asOf = base.AddDate(0, 0, today)
+	on := func(day int, kind eventKind) CareEvent {
+		return CareEvent{Kind: kind, Time: base.AddDate(0, 0, day), Task: eventWatered}
+	}
+	snooze := func(day, days int) CareEvent {
+		e := on(day, eventSnoozed)
+		e.Days = days
+		return e
+	}
+
+	for _, tt := range []struct {
+		name      string
+		intervals string
+		events    []CareEvent
+		want      window
+	}{
+		{name: "not deferred", events: nil, want: window{Opens: 7, Closes: 7}},
+		{name: "snoozed before due", events: []CareEvent{snooze(3, 2)}, want: window{Opens: 9, Closes: 9}},
+		{name: "snoozed on the due day", events: []CareEvent{snooze(7, 2)}, want: window{Opens: 9, Closes: 9}},
+		{name: "snoozed when overdue", events: []CareEvent{snooze(10, 2)}, want: window{Opens: 12, Closes: 12}},
+		{name: "snoozed twice", events: []CareEvent{snooze(3, 2), snooze(8, 3)}, want: window{Opens: 12, Closes: 12}},
+		{name: "snoozed within a window", intervals: "5-8", events: []CareEvent{snooze(6, 2)}, want: window{Opens: 8, Closes: 10}},
+		{name: "skipped before due", events: []CareEvent{on(4, eventSkipped)}, want: window{Opens: 14, Closes: 14}},
+		{name: "skipped when overdue", events: []CareEvent{on(10, eventSkipped)}, want: window{Opens: 17, Closes: 17}},
+		{name: "skipped twice", events: []CareEvent{on(4, eventSkipped), on(8, eventSkipped)}, want: window{Opens: 21, Closes: 21}},
+		{name: "skipped a snoozed one", events: []CareEvent{snooze(3, 2), on(5, eventSkipped)}, want: window{Opens: 16, Closes: 16}},
+		{name: "snoozed after a skip", events: []CareEvent{on(4, eventSkipped), snooze(5, 2)}, want: window{Opens: 16, Closes: 16}},
+		{name: "skipped within a window", intervals: "5-8", events: []CareEvent{on(6, eventSkipped)}, want: window{Opens: 11, Closes: 14}},
+		{name: "watered after a skip", events: []CareEvent{on(4, eventSkipped), {Kind: eventWatered, Time: base.AddDate(0, 0, 9)}}, want: window{Opens: 16, Closes: 16}},
+		{
+			name:   "deferring another task",
+			events: []CareEvent{{Kind: eventSkipped, Time: base.AddDate(0, 0, 4), Task: eventFertilized}},
+			want:   window{Opens: 7, Closes: 7},
+		},
+	} {
+		if tt.intervals == "" {
+			tt.intervals = "7"
+		}
+		intervals, err := parseSeasonalIntervals(tt.intervals)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p := Plant{WateringIntervals: intervals}
+		p.appendEvent(CareEvent{Kind: eventWatered, Time: base})
+		for _, e := range tt.events {
+			p.appendEvent(e)
+		}
+		want := window{Opens: tt.want.Opens - today, Closes: tt.want.Closes - today}
+		if got, ok := p.scheduled(sched, eventWatered); !ok || got != want {
+			t.Fatalf("%s: expected=%+v, got=%+v (%v)", tt.name, want, got, ok)
+		}
+	}
+}
+
+func TestParseTask(t *testing.T) {
+	for s, want := range map[string]eventKind{
+		"water":       eventWatered,
+		"Watering":    eventWatered,
+		"fertilized":  eventFertilized,
+		"FERTILIZING": eventFertilized,
+	} {
+		if got, err := parseTask(s); err != nil || got != want {
+			t.Fatalf("%s: expected=%s, got=%s (%v)", s, want, got, err)
+		}
+	}
+	for _, s := range []string{"", "repot", "wat"} {
+		if _, err := parseTask(s); err == nil {
+			t.Fatalf("expected an error for task %q", s)
+		}
+	}
+}
+
+func TestDeferPrompt(t *testing.T) {
+	pDB := &PlantDB{}
+	p := &Plant{Name: "Fred"}
+
+	ip := newDeferPrompt(pDB, p, eventSnoozed)
+	ip.inputs[1].SetValue("0")
+	if _, err := ip.confirmAction(ip); err == nil {
+		t.Fatal("expected an error for snoozing by 0 days")
+	}
+	ip.inputs[0].SetValue("fertilize")
+	ip.inputs[1].SetValue("3")
+	if _, err := ip.confirmAction(ip); err != nil {
+		t.Fatal(err)
+	}
+	ip = newDeferPrompt(pDB, p, eventSkipped)
+	if _, err := ip.confirmAction(ip); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []CareEvent{
+		{Kind: eventSnoozed, Task: eventFertilized, Days: 3},
+		{Kind: eventSkipped, Task: eventWatered},
+	}
+	if len(p.History) != len(want) {
+		t.Fatalf("expected %d events, got %+v", len(want), p.History)
+	}
+	for i, e := range p.History {
+		if e.Kind != want[i].Kind || e.Task != want[i].Task || e.Days != want[i].Days {
+			t.Fatalf("event %d: expected=%+v, got=%+v", i, want[i], e)
+		}
+	}
+}
+
+func TestDeferCommands(t *testing.T) {
+	sched, err := newScheduler(defaultConfig().Seasons)
+	if err != nil {
+		t.Fatal(err)
+	}
+	location := filepath.Join(t.TempDir(), "plants.json")
+	pDB, err := openDB(location, sched)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pDB.Plants = []*Plant{{ID: "0000fred", Name: "Fred"}}
+	if err := pDB.Save(); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"snooze", "water", "Fred", "-days", "2"},
+		{"skip", "fertilize", "Fred", "2024-06-01"},
+	} {
+		if code := runCommand(pDB, args); code != 0 {
+			t.Fatalf("%v failed with exit code %d", args, code)
+		}
+	}
+	for _, args := range [][]string{
+		{"snooze", "water", "Fred", "-days", "0"},
+		{"snooze", "repot", "Fred"},
+		{"skip"},
+	} {
+		if code := runCommand(pDB, args); code == 0 {
+			t.Fatalf("expected %v to fail", args)
+		}
+	}
+
+	pDB, err = openDB(location, sched)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pDB.Close()
+	h := pDB.Plants[0].History
+	if len(h) != 2 {
+		t.Fatalf("expected 2 events, got %+v", h)
+	}
+	// sorted by time, the skip has been backdated.
+	if h[0].Kind != eventSkipped || h[0].Task != eventFertilized || h[0].Time.Format("2006-01-02") != "2024-06-01" {
+		t.Fatalf("wrong skip: %+v", h[0])
+	}
+	if h[1].Kind != eventSnoozed || h[1].Task != eventWatered || h[1].Days != 2 {
+		t.Fatalf("wrong snooze: %+v", h[1])
+	}
+}
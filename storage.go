@@ -0,0 +1,76 @@
+package main
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Storage persists a PlantDB.
+type Storage interface {
+	// Load reads the whole DB.
+	Load() (*PlantDB, error)
+	// Save replaces the stored DB with pDB. Unless force is set,
+	// errDBModified is returned if the DB has been modified by someone
+	// else since it has last been loaded or saved.
+	Save(pDB *PlantDB, force bool) error
+	// AppendEvent records a single event for the plant with the given
+	// ID, without rewriting the whole DB.
+	AppendEvent(plantID string, e CareEvent) error
+	// Query returns all events that match q, ordered by time.
+	Query(q eventQuery) ([]eventRecord, error)
+	// Modified reports whether the DB has been modified by someone else
+	// since it has last been loaded or saved.
+	Modified() (bool, error)
+	Close() error
+}
+
+// openStorage opens the storage at location. Files ending in .db,
+// .sqlite or .sqlite3 are SQLite databases, everything else is JSON.
+func openStorage(location string) (Storage, error) {
+	switch strings.ToLower(filepath.Ext(location)) {
+	case ".db", ".sqlite", ".sqlite3":
+		return openSQLiteStorage(location)
+	default:
+		return &jsonStorage{location: location}, nil
+	}
+}
+
+// eventQuery selects events. Zero fields match everything.
+type eventQuery struct {
+	PlantID string
+	Kind    eventKind
+	// Since and Until limit the events to [Since, Until).
+	Since time.Time
+	Until time.Time
+}
+
+type eventRecord struct {
+	PlantID string `json:"plant_id"`
+	Plant   string `json:"plant"`
+	CareEvent
+}
+
+func (q eventQuery) matches(plantID string, e CareEvent) bool {
+	return (q.PlantID == "" || q.PlantID == plantID) &&
+		(q.Kind == "" || q.Kind == e.Kind) &&
+		(q.Since.IsZero() || !e.Time.Before(q.Since)) &&
+		(q.Until.IsZero() || e.Time.Before(q.Until))
+}
+
+// queryPlants runs q against the given plants in memory.
+func queryPlants(plants []*Plant, q eventQuery) []eventRecord {
+	var records []eventRecord
+	for _, p := range plants {
+		for _, e := range p.History {
+			if q.matches(p.ID, e) {
+				records = append(records, eventRecord{PlantID: p.ID, Plant: p.Name, CareEvent: e})
+			}
+		}
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Time.Before(records[j].Time)
+	})
+	return records
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// jsonStorage stores the whole DB in a single JSON file. Access is
+// guarded by an advisory lock on a lock file next to it.
+type jsonStorage struct {
+	location string
+	// disk is the state of the file as of the last load / save.
+	disk fileState
+}
+
+// fileState identifies the content of the DB file on disk.
+type fileState struct {
+	modTime time.Time
+	size    int64
+	hash    [sha256.Size]byte
+}
+
+func (js *jsonStorage) lockFile() string {
+	return js.location + ".lock"
+}
+
+func (js *jsonStorage) Load() (*PlantDB, error) {
+	unlock, err := lockFile(js.lockFile(), false)
+	if err != nil {
+		return nil, fmt.Errorf("could not lock DB file: %w", err)
+	}
+	defer unlock()
+
+	pDB, state, err := js.read()
+	if err != nil {
+		return nil, err
+	}
+	js.disk = state
+	return pDB, nil
+}
+
+// read reads the DB file, the caller needs to hold the lock. A missing
+// file results in an empty DB. If the file needs to be migrated, a backup
+// of it is made first.
+func (js *jsonStorage) read() (*PlantDB, fileState, error) {
+	data, err := os.ReadFile(js.location)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &PlantDB{Version: dbVersion}, fileState{}, nil
+		}
+		return nil, fileState{}, fmt.Errorf("could not read DB file: %w", err)
+	}
+	state, err := statFile(js.location, data)
+	if err != nil {
+		return nil, fileState{}, fmt.Errorf("could not stat DB file: %w", err)
+	}
+
+	pDB, version, err := unmarshalDB(data)
+	if err != nil {
+		var tooNew errDBTooNew
+		if errors.As(err, &tooNew) {
+			return nil, fileState{}, err
+		}
+		return nil, fileState{}, fmt.Errorf("malformatted DB file: %w", err)
+	}
+	if version < dbVersion {
+		if err := backupFile(js.location, data, version); err != nil {
+			return nil, fileState{}, err
+		}
+	}
+	return pDB, state, nil
+}
+
+// Save writes the DB to the file. The file is replaced atomically, so a
+// crash while saving never leaves a half-written DB behind.
+func (js *jsonStorage) Save(pDB *PlantDB, force bool) error {
+	unlock, err := lockFile(js.lockFile(), true)
+	if err != nil {
+		return fmt.Errorf("could not lock DB file: %w", err)
+	}
+	defer unlock()
+
+	return js.write(pDB, force)
+}
+
+// write is Save, the caller needs to hold the lock.
+func (js *jsonStorage) write(pDB *PlantDB, force bool) error {
+	if !force {
+		modified, err := js.Modified()
+		if err != nil {
+			return fmt.Errorf("could not check DB file: %w", err)
+		}
+		if modified {
+			return errDBModified
+		}
+	}
+
+	data, err := json.Marshal(pDB)
+	if err != nil {
+		return fmt.Errorf("could not marshal plantDB to JSON: %w", err)
+	}
+	if err := writeFileAtomic(js.location, data, 0600); err != nil {
+		return fmt.Errorf("could not write DB file: %w", err)
+	}
+	js.disk, err = statFile(js.location, data)
+	if err != nil {
+		return fmt.Errorf("could not stat DB file: %w", err)
+	}
+	return nil
+}
+
+// AppendEvent needs to rewrite the whole file, JSON can't be appended to.
+func (js *jsonStorage) AppendEvent(plantID string, e CareEvent) error {
+	unlock, err := lockFile(js.lockFile(), true)
+	if err != nil {
+		return fmt.Errorf("could not lock DB file: %w", err)
+	}
+	defer unlock()
+
+	modified, err := js.Modified()
+	if err != nil {
+		return fmt.Errorf("could not check DB file: %w", err)
+	}
+	if modified {
+		return errDBModified
+	}
+
+	pDB, _, err := js.read()
+	if err != nil {
+		return err
+	}
+	for _, p := range pDB.Plants {
+		if p.ID == plantID {
+			p.History = append(p.History, e)
+			pDB.normalise()
+			return js.write(pDB, false)
+		}
+	}
+	return fmt.Errorf("no plant with ID %s", plantID)
+}
+
+func (js *jsonStorage) Query(q eventQuery) ([]eventRecord, error) {
+	unlock, err := lockFile(js.lockFile(), false)
+	if err != nil {
+		return nil, fmt.Errorf("could not lock DB file: %w", err)
+	}
+	defer unlock()
+
+	pDB, _, err := js.read()
+	if err != nil {
+		return nil, err
+	}
+	return queryPlants(pDB.Plants, q), nil
+}
+
+// Modified compares the file on disk with the state of the last load /
+// save, first by modification time and size, then by content.
+func (js *jsonStorage) Modified() (bool, error) {
+	fi, err := os.Stat(js.location)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return !js.disk.modTime.IsZero(), nil
+		}
+		return false, err
+	}
+	if fi.ModTime().Equal(js.disk.modTime) && fi.Size() == js.disk.size {
+		return false, nil
+	}
+
+	// the file has been touched, but that doesn't mean it's different.
+	data, err := os.ReadFile(js.location)
+	if err != nil {
+		return false, err
+	}
+	return sha256.Sum256(data) != js.disk.hash, nil
+}
+
+func (js *jsonStorage) Close() error {
+	return nil
+}
+
+func statFile(name string, data []byte) (fileState, error) {
+	fi, err := os.Stat(name)
+	if err != nil {
+		return fileState{}, err
+	}
+	return fileState{
+		modTime: fi.ModTime(),
+		size:    fi.Size(),
+		hash:    sha256.Sum256(data),
+	}, nil
+}
+
+// writeFileAtomic writes data to a temporary file next to name and
+// renames it to name once the data has been synced to disk.
+func writeFileAtomic(name string, data []byte, perm os.FileMode) (err error) {
+	dir, base := filepath.Split(name)
+	if dir == "" {
+		dir = "."
+	}
+	f, err := os.CreateTemp(dir, "."+base+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = f.Close()
+			_ = os.Remove(f.Name())
+		}
+	}()
+
+	if _, err := f.Write(data); err != nil {
+		return err
+	}
+	if err := f.Chmod(perm); err != nil {
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(f.Name(), name); err != nil {
+		return err
+	}
+
+	// sync the directory too, otherwise the rename itself might get lost.
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
@@ -0,0 +1,618 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS plants (
+	position INTEGER PRIMARY KEY,
+	name     TEXT NOT NULL,
+	data     TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS events (
+	plant   INTEGER NOT NULL REFERENCES plants (position) ON DELETE CASCADE,
+	kind    TEXT NOT NULL,
+	unix    INTEGER NOT NULL,
+	time    TEXT NOT NULL,
+	amount  TEXT NOT NULL DEFAULT '',
+	product TEXT NOT NULL DEFAULT '',
+	actor   TEXT NOT NULL DEFAULT '',
+	note    TEXT NOT NULL DEFAULT '',
+	fertilizer    TEXT NOT NULL DEFAULT '',
+	dilution      TEXT NOT NULL DEFAULT '',
+	npk           TEXT NOT NULL DEFAULT '',
+	from_pot_size INTEGER NOT NULL DEFAULT 0,
+	pot_size      INTEGER NOT NULL DEFAULT 0,
+	pot_material  TEXT NOT NULL DEFAULT '',
+	soil_mix      TEXT NOT NULL DEFAULT '',
+	task          TEXT NOT NULL DEFAULT '',
+	days          INTEGER NOT NULL DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS events_by_time ON events (kind, unix);
+CREATE INDEX IF NOT EXISTS events_by_plant ON events (plant);
+`
+
+// sqliteEventColumns have been added to the events table later on, they
+// are added to existing DBs when opening them.
+var sqliteEventColumns = []struct{ name, definition string }{
+	{"amount", "TEXT NOT NULL DEFAULT ''"},
+	{"product", "TEXT NOT NULL DEFAULT ''"},
+	{"actor", "TEXT NOT NULL DEFAULT ''"},
+	{"note", "TEXT NOT NULL DEFAULT ''"},
+	{"fertilizer", "TEXT NOT NULL DEFAULT ''"},
+	{"dilution", "TEXT NOT NULL DEFAULT ''"},
+	{"npk", "TEXT NOT NULL DEFAULT ''"},
+	{"from_pot_size", "INTEGER NOT NULL DEFAULT 0"},
+	{"pot_size", "INTEGER NOT NULL DEFAULT 0"},
+	{"pot_material", "TEXT NOT NULL DEFAULT ''"},
+	{"soil_mix", "TEXT NOT NULL DEFAULT ''"},
+	{"task", "TEXT NOT NULL DEFAULT ''"},
+	{"days", "INTEGER NOT NULL DEFAULT 0"},
+}
+
+// sqliteStorage stores plants as JSON documents and their events as
+// separate rows, so events can be appended and queried without touching
+// the whole DB.
+type sqliteStorage struct {
+	db       *sql.DB
+	location string
+	// version is the data_version as of the last load / save. It changes
+	// whenever another connection commits to the DB.
+	version int64
+	// stored are the plants by ID as of the last load / save, so that
+	// only the changes need to be written. It's nil if they aren't known,
+	// e.g. after a migration.
+	stored map[string]storedPlant
+}
+
+// storedPlant is a plant as it is stored in the DB.
+type storedPlant struct {
+	position int
+	data     string
+	events   []CareEvent
+}
+
+// plantRow returns the data column of p, the events are stored
+// separately.
+func plantRow(p *Plant) (string, error) {
+	withoutEvents := *p
+	withoutEvents.History = nil
+	data, err := json.Marshal(&withoutEvents)
+	if err != nil {
+		return "", fmt.Errorf("could not marshal plant: %w", err)
+	}
+	return string(data), nil
+}
+
+// storedPlants returns the plants of pDB as they are stored, or nil if
+// they can't be told apart by their IDs.
+func storedPlants(pDB *PlantDB) map[string]storedPlant {
+	stored := make(map[string]storedPlant, len(pDB.Plants))
+	for i, p := range pDB.Plants {
+		if _, taken := stored[p.ID]; taken || p.ID == "" {
+			return nil
+		}
+		data, err := plantRow(p)
+		if err != nil {
+			return nil
+		}
+		stored[p.ID] = storedPlant{
+			position: i,
+			data:     data,
+			events:   append([]CareEvent(nil), p.History...),
+		}
+	}
+	return stored
+}
+
+func openSQLiteStorage(location string) (*sqliteStorage, error) {
+	db, err := sql.Open("sqlite", location)
+	if err != nil {
+		return nil, fmt.Errorf("could not open SQLite DB: %w", err)
+	}
+	// data_version is per connection, so we need to stick to one.
+	db.SetMaxOpenConns(1)
+
+	var exists bool
+	if err := db.QueryRow("SELECT count(*) > 0 FROM sqlite_master WHERE type = 'table' AND name = 'plants'").Scan(&exists); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("could not open SQLite DB: %w", err)
+	}
+	stmts := []string{"PRAGMA foreign_keys = ON", "PRAGMA busy_timeout = 5000", sqliteSchema}
+	if !exists {
+		stmts = append(stmts, fmt.Sprintf("PRAGMA user_version = %d", dbVersion))
+	}
+	for _, stmt := range stmts {
+		if _, err := db.Exec(stmt); err != nil {
+			_ = db.Close()
+			return nil, fmt.Errorf("could not set up SQLite DB: %w", err)
+		}
+	}
+	if err := addEventColumns(db); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("could not set up SQLite DB: %w", err)
+	}
+	return &sqliteStorage{db: db, location: location}, nil
+}
+
+// addEventColumns adds the sqliteEventColumns that are missing.
+func addEventColumns(db *sql.DB) error {
+	rows, err := db.Query("SELECT name FROM pragma_table_info('events')")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	existing := map[string]bool{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return err
+		}
+		existing[name] = true
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	for _, column := range sqliteEventColumns {
+		if existing[column.name] {
+			continue
+		}
+		if _, err := db.Exec("ALTER TABLE events ADD COLUMN " + column.name + " " + column.definition); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func dataVersion(conn *sql.Conn) (int64, error) {
+	var v int64
+	err := conn.QueryRowContext(context.Background(), "PRAGMA data_version").Scan(&v)
+	return v, err
+}
+
+// withTx runs fn in an immediate transaction, which takes the write lock
+// right away instead of on the first write.
+func (ss *sqliteStorage) withTx(fn func(conn *sql.Conn) error) (err error) {
+	ctx := context.Background()
+	conn, err := ss.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "BEGIN IMMEDIATE"); err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_, _ = conn.ExecContext(ctx, "ROLLBACK")
+		}
+	}()
+
+	if err := fn(conn); err != nil {
+		return err
+	}
+	_, err = conn.ExecContext(ctx, "COMMIT")
+	return err
+}
+
+// Load migrates the plant documents with the same migrations as the JSON
+// storage, the format version is stored in the user_version.
+func (ss *sqliteStorage) Load() (*PlantDB, error) {
+	var (
+		pDB     *PlantDB
+		version int
+	)
+	err := ss.withTx(func(conn *sql.Conn) error {
+		ctx := context.Background()
+		if err := conn.QueryRowContext(ctx, "PRAGMA user_version").Scan(&version); err != nil {
+			return err
+		}
+		if version > dbVersion {
+			return errDBTooNew{version: version}
+		}
+
+		rows, err := conn.QueryContext(ctx, "SELECT data FROM plants ORDER BY position")
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		// kept raw so that the migrations get the numbers as they are.
+		var plants []map[string]json.RawMessage
+		for rows.Next() {
+			var data string
+			if err := rows.Scan(&data); err != nil {
+				return err
+			}
+			var plant map[string]json.RawMessage
+			if err := json.Unmarshal([]byte(data), &plant); err != nil {
+				return fmt.Errorf("malformatted plant: %w", err)
+			}
+			plants = append(plants, plant)
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		events, err := conn.QueryContext(ctx, "SELECT plant, "+eventColumns+" FROM events ORDER BY unix")
+		if err != nil {
+			return err
+		}
+		defer events.Close()
+		history := make([][]CareEvent, len(plants))
+		for events.Next() {
+			var plant int
+			e, err := scanEvent(events, &plant)
+			if err != nil {
+				return err
+			}
+			if plant < 0 || plant >= len(plants) {
+				return fmt.Errorf("event for unknown plant %d", plant)
+			}
+			history[plant] = append(history[plant], e)
+		}
+		if err := events.Err(); err != nil {
+			return err
+		}
+
+		// the events are part of the plant documents for the migrations.
+		for i, plant := range plants {
+			if plant["events"], err = json.Marshal(history[i]); err != nil {
+				return err
+			}
+		}
+		doc, err := json.Marshal(map[string]any{"version": version, "plants": plants})
+		if err != nil {
+			return err
+		}
+		if pDB, _, err = unmarshalDB(doc); err != nil {
+			return fmt.Errorf("malformatted plants: %w", err)
+		}
+		ss.stored = nil
+		if version == dbVersion {
+			ss.stored = storedPlants(pDB)
+		}
+
+		ss.version, err = dataVersion(conn)
+		return err
+	})
+	if err != nil {
+		var tooNew errDBTooNew
+		if errors.As(err, &tooNew) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("could not load SQLite DB: %w", err)
+	}
+
+	if version < dbVersion && len(pDB.Plants) > 0 {
+		if err := ss.backup(version); err != nil {
+			return nil, err
+		}
+	}
+	return pDB, nil
+}
+
+// backup copies the DB before it gets migrated, unless a backup of that
+// version exists already. It can't be run inside a transaction.
+func (ss *sqliteStorage) backup(version int) error {
+	backup := fmt.Sprintf("%s.v%d.bak", ss.location, version)
+	if _, err := os.Stat(backup); err == nil {
+		return nil
+	}
+	if _, err := ss.db.Exec("VACUUM INTO ?", backup); err != nil {
+		return fmt.Errorf("could not back up DB before migrating it: %w", err)
+	}
+	return nil
+}
+
+func (ss *sqliteStorage) Save(pDB *PlantDB, force bool) error {
+	err := ss.withTx(func(conn *sql.Conn) error {
+		ctx := context.Background()
+		version, err := dataVersion(conn)
+		if err != nil {
+			return err
+		}
+		if !force && version != ss.version {
+			return errDBModified
+		}
+
+		if _, err := conn.ExecContext(ctx, fmt.Sprintf("PRAGMA user_version = %d", dbVersion)); err != nil {
+			return err
+		}
+		stored := storedPlants(pDB)
+		if force || ss.stored == nil || stored == nil {
+			err = rewritePlants(conn, pDB)
+		} else {
+			err = ss.updatePlants(conn, pDB)
+		}
+		if err != nil {
+			return err
+		}
+		ss.version, ss.stored = version, stored
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, errDBModified) {
+			return err
+		}
+		return fmt.Errorf("could not save SQLite DB: %w", err)
+	}
+	return nil
+}
+
+// rewritePlants replaces all plants and events with the ones of pDB.
+func rewritePlants(conn *sql.Conn, pDB *PlantDB) error {
+	ctx := context.Background()
+	if _, err := conn.ExecContext(ctx, "DELETE FROM events"); err != nil {
+		return err
+	}
+	if _, err := conn.ExecContext(ctx, "DELETE FROM plants"); err != nil {
+		return err
+	}
+	for i, p := range pDB.Plants {
+		if err := insertPlant(conn, i, p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updatePlants writes the changes since the last load / save: plants
+// that have been added, removed, moved or changed, and new events. Only
+// the events of plants whose past events have been changed or removed
+// are rewritten.
+func (ss *sqliteStorage) updatePlants(conn *sql.Conn, pDB *PlantDB) error {
+	ctx := context.Background()
+	// events reference plants by position, which are changed in two
+	// steps below.
+	if _, err := conn.ExecContext(ctx, "PRAGMA defer_foreign_keys = ON"); err != nil {
+		return err
+	}
+
+	current := make(map[string]int, len(pDB.Plants))
+	for i, p := range pDB.Plants {
+		current[p.ID] = i
+	}
+	for id, s := range ss.stored {
+		i, ok := current[id]
+		switch {
+		case !ok:
+			if err := deletePlant(conn, s.position); err != nil {
+				return err
+			}
+		case i != s.position:
+			// moved to a negative position first, as positions are
+			// unique.
+			if err := movePlant(conn, s.position, -1-i); err != nil {
+				return err
+			}
+		}
+	}
+	for _, stmt := range []string{
+		"UPDATE plants SET position = -1 - position WHERE position < 0",
+		"UPDATE events SET plant = -1 - plant WHERE plant < 0",
+	} {
+		if _, err := conn.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+
+	for i, p := range pDB.Plants {
+		s, ok := ss.stored[p.ID]
+		if !ok {
+			if err := insertPlant(conn, i, p); err != nil {
+				return err
+			}
+			continue
+		}
+		data, err := plantRow(p)
+		if err != nil {
+			return err
+		}
+		if data != s.data {
+			if _, err := conn.ExecContext(ctx,
+				"UPDATE plants SET name = ?, data = ? WHERE position = ?", p.Name, data, i,
+			); err != nil {
+				return err
+			}
+		}
+
+		added := p.History
+		if isPrefix(s.events, p.History) {
+			added = p.History[len(s.events):]
+		} else if _, err := conn.ExecContext(ctx, "DELETE FROM events WHERE plant = ?", i); err != nil {
+			return err
+		}
+		for _, e := range added {
+			if err := insertEvent(conn, i, e); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// isPrefix returns true if events are the first ones of all.
+func isPrefix(events, all []CareEvent) bool {
+	if len(events) > len(all) {
+		return false
+	}
+	for i := range events {
+		a, errA := json.Marshal(events[i])
+		b, errB := json.Marshal(all[i])
+		if errA != nil || errB != nil || string(a) != string(b) {
+			return false
+		}
+	}
+	return true
+}
+
+func insertPlant(conn *sql.Conn, position int, p *Plant) error {
+	data, err := plantRow(p)
+	if err != nil {
+		return err
+	}
+	if _, err := conn.ExecContext(context.Background(),
+		"INSERT INTO plants (position, name, data) VALUES (?, ?, ?)",
+		position, p.Name, data,
+	); err != nil {
+		return err
+	}
+	for _, e := range p.History {
+		if err := insertEvent(conn, position, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func deletePlant(conn *sql.Conn, position int) error {
+	ctx := context.Background()
+	if _, err := conn.ExecContext(ctx, "DELETE FROM events WHERE plant = ?", position); err != nil {
+		return err
+	}
+	_, err := conn.ExecContext(ctx, "DELETE FROM plants WHERE position = ?", position)
+	return err
+}
+
+func movePlant(conn *sql.Conn, from, to int) error {
+	ctx := context.Background()
+	if _, err := conn.ExecContext(ctx, "UPDATE plants SET position = ? WHERE position = ?", to, from); err != nil {
+		return err
+	}
+	_, err := conn.ExecContext(ctx, "UPDATE events SET plant = ? WHERE plant = ?", to, from)
+	return err
+}
+
+// eventColumns are the columns scanned by scanEvent.
+const eventColumns = "kind, time, amount, product, actor, note, fertilizer, dilution, npk, " +
+	"from_pot_size, pot_size, pot_material, soil_mix, task, days"
+
+func insertEvent(conn *sql.Conn, plant int, e CareEvent) error {
+	_, err := conn.ExecContext(context.Background(),
+		"INSERT INTO events (plant, unix, "+eventColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		plant, e.Time.Unix(), string(e.Kind), e.Time.Format(time.RFC3339Nano),
+		e.Amount, e.Product, e.Actor, e.Note, string(e.Fertilizer), e.Dilution, e.NPK,
+		e.FromPotSize, e.PotSize, e.PotMaterial, e.SoilMix, string(e.Task), e.Days,
+	)
+	return err
+}
+
+// scanEvent scans the eventColumns, preceded by dest.
+func scanEvent(rows *sql.Rows, dest ...any) (CareEvent, error) {
+	var (
+		e  CareEvent
+		ts string
+	)
+	dest = append(dest, &e.Kind, &ts, &e.Amount, &e.Product, &e.Actor, &e.Note, &e.Fertilizer, &e.Dilution, &e.NPK,
+		&e.FromPotSize, &e.PotSize, &e.PotMaterial, &e.SoilMix, &e.Task, &e.Days)
+	if err := rows.Scan(dest...); err != nil {
+		return e, err
+	}
+	t, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		return e, fmt.Errorf("malformatted event time: %w", err)
+	}
+	e.Time = t
+	return e, nil
+}
+
+// AppendEvent inserts the event without checking for modifications by
+// others; appending doesn't conflict with them.
+func (ss *sqliteStorage) AppendEvent(plantID string, e CareEvent) error {
+	err := ss.withTx(func(conn *sql.Conn) error {
+		var position int
+		if err := conn.QueryRowContext(context.Background(),
+			"SELECT position FROM plants WHERE json_extract(data, '$.id') = ?", plantID,
+		).Scan(&position); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("no plant with ID %s", plantID)
+			}
+			return err
+		}
+		return insertEvent(conn, position, e)
+	})
+	if err != nil {
+		return fmt.Errorf("could not append event: %w", err)
+	}
+	if s, ok := ss.stored[plantID]; ok {
+		s.events = append(s.events, e)
+		ss.stored[plantID] = s
+	}
+	return nil
+}
+
+func (ss *sqliteStorage) Query(q eventQuery) ([]eventRecord, error) {
+	var (
+		where []string
+		args  []any
+	)
+	if q.PlantID != "" {
+		where = append(where, "json_extract(p.data, '$.id') = ?")
+		args = append(args, q.PlantID)
+	}
+	if q.Kind != "" {
+		where = append(where, "e.kind = ?")
+		args = append(args, string(q.Kind))
+	}
+	if !q.Since.IsZero() {
+		where = append(where, "e.unix >= ?")
+		args = append(args, q.Since.Unix())
+	}
+	if !q.Until.IsZero() {
+		where = append(where, "e.unix < ?")
+		args = append(args, q.Until.Unix())
+	}
+
+	query := "SELECT json_extract(p.data, '$.id'), p.name, " + eventColumns + " FROM events e JOIN plants p ON p.position = e.plant"
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY e.unix"
+
+	rows, err := ss.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("could not query events: %w", err)
+	}
+	defer rows.Close()
+
+	var records []eventRecord
+	for rows.Next() {
+		var r eventRecord
+		if r.CareEvent, err = scanEvent(rows, &r.PlantID, &r.Plant); err != nil {
+			return nil, err
+		}
+		records = append(records, r)
+	}
+	return records, rows.Err()
+}
+
+func (ss *sqliteStorage) Modified() (bool, error) {
+	conn, err := ss.db.Conn(context.Background())
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	v, err := dataVersion(conn)
+	if err != nil {
+		return false, err
+	}
+	return v != ss.version, nil
+}
+
+func (ss *sqliteStorage) Close() error {
+	return ss.db.Close()
+}
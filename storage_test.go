@@ -0,0 +1,165 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestSQLiteSaveChanges(t *testing.T) {
+	location := filepath.Join(t.TempDir(), "plants.db")
+	pDB, err := openDB(location, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	day := time.Date(2024, 6, 1, 12, 0, 0, 0, time.Local)
+	for i, name := range []string{"a", "b", "c"} {
+		pDB.Plants = append(pDB.Plants, &Plant{
+			ID:      "0000000" + name,
+			Name:    name,
+			History: []CareEvent{{Kind: eventWatered, Time: day.AddDate(0, 0, i)}},
+		})
+	}
+	if err := pDB.Save(); err != nil {
+		t.Fatal(err)
+	}
+	ss := pDB.storage.(*sqliteStorage)
+	rowids := func(name string) []int64 {
+		rows, err := ss.db.Query("SELECT e.rowid FROM events e JOIN plants p ON p.position = e.plant WHERE p.name = ? ORDER BY e.rowid", name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rows.Close()
+		var ids []int64
+		for rows.Next() {
+			var id int64
+			if err := rows.Scan(&id); err != nil {
+				t.Fatal(err)
+			}
+			ids = append(ids, id)
+		}
+		return ids
+	}
+	before := rowids("c")
+
+	// removing a moves the others, b gets a new event and c is renamed.
+	pDB.Plants = pDB.Plants[1:]
+	pDB.Plants[0].appendEvent(CareEvent{Kind: eventWatered, Time: day.AddDate(0, 0, 5)})
+	pDB.Plants[1].Name = "d"
+	if err := pDB.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if after := rowids("d"); len(after) != 1 || after[0] != before[0] {
+		t.Fatalf("events of an unchanged plant have been rewritten: %v, before %v", after, before)
+	}
+	if err := pDB.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	pDB, err = openDB(location, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pDB.Close()
+	if len(pDB.Plants) != 2 || pDB.Plants[0].Name != "b" || pDB.Plants[1].Name != "d" {
+		t.Fatalf("wrong plants after saving changes: %+v", pDB.Plants)
+	}
+	if n := len(pDB.Plants[0].History); n != 2 {
+		t.Fatalf("expected 2 events of b, got %d", n)
+	}
+	if n := len(pDB.Plants[1].History); n != 1 {
+		t.Fatalf("expected 1 event of d, got %d", n)
+	}
+}
+
+// tempFiles returns the files in dir that writeFileAtomic left behind.
+func tempFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	matches, err := filepath.Glob(filepath.Join(dir, ".*.tmp-*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return matches
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "plants.json")
+	for _, content := range []string{"first", "second"} {
+		if err := writeFileAtomic(name, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != content {
+			t.Fatalf("expected=%q, got=%q", content, data)
+		}
+	}
+	if fi, err := os.Stat(name); err != nil || fi.Mode().Perm() != 0600 {
+		t.Fatalf("wrong permissions: %v (%v)", fi.Mode(), err)
+	}
+	if left := tempFiles(t, dir); len(left) != 0 {
+		t.Fatalf("temporary files have been left behind: %v", left)
+	}
+
+	// a directory can't be replaced by a file, so the rename fails.
+	blocked := filepath.Join(dir, "blocked")
+	if err := os.MkdirAll(filepath.Join(blocked, "keep"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeFileAtomic(blocked, []byte("data"), 0600); err == nil {
+		t.Fatal("expected the write to fail")
+	}
+	if left := tempFiles(t, dir); len(left) != 0 {
+		t.Fatalf("temporary files have been left behind: %v", left)
+	}
+	if _, err := os.Stat(filepath.Join(blocked, "keep")); err != nil {
+		t.Fatalf("the original has been touched: %v", err)
+	}
+	if data, err := os.ReadFile(name); err != nil || string(data) != "second" {
+		t.Fatalf("other files have been touched: %q (%v)", data, err)
+	}
+}
+
+func TestAutosave(t *testing.T) {
+	sched, err := newScheduler(defaultConfig().Seasons)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	location := filepath.Join(dir, "plants.json")
+	pDB, err := openDB(location, sched)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pDB.Plants = []*Plant{{ID: "0000fred", Name: "Fred"}}
+	if err := pDB.Save(); err != nil {
+		t.Fatal(err)
+	}
+	h, err := newHistory(pDB, 10, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sp := newShowPlants(pDB, newKeyMap(defaultConfig().Keys, nil), h)
+
+	// watering the selected plant is saved right away.
+	sp.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("w")})
+	if sp.err != nil {
+		t.Fatal(sp.err)
+	}
+	stored, err := openDB(location, sched)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h := stored.Plants[0].History; len(h) != 1 || h[0].Kind != eventWatered {
+		t.Fatalf("watering has not been saved: %+v", h)
+	}
+	if left := tempFiles(t, dir); len(left) != 0 {
+		t.Fatalf("temporary files have been left behind: %v", left)
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// careTask is a custom task from the config, see Task.
+type careTask struct {
+	kind  eventKind
+	color lipgloss.Color
+	// intervals are used for plants that don't have their own.
+	intervals SeasonalIntervals
+}
+
+// customTasks are the configured tasks, set up by applyTasks.
+var customTasks []careTask
+
+// defaultTaskColor is used for tasks that don't have a colour configured.
+const defaultTaskColor = "#5f5f87"
+
+// applyTasks sets up customTasks, the tasks have been validated with the
+// config already.
+func applyTasks(tasks []Task) {
+	customTasks = nil
+	for _, t := range tasks {
+		color := t.Color
+		if color == "" {
+			color = defaultTaskColor
+		}
+		intervals, _ := parseSeasonalIntervals(t.Intervals)
+		customTasks = append(customTasks, careTask{
+			kind:      eventKind(t.Name),
+			color:     lipgloss.Color(color),
+			intervals: intervals,
+		})
+	}
+}
+
+// allEventKinds returns the builtin event kinds followed by the custom
+// tasks.
+func allEventKinds() []eventKind {
+	kinds := append([]eventKind{}, eventKinds...)
+	for _, t := range customTasks {
+		kinds = append(kinds, t.kind)
+	}
+	return kinds
+}
+
+func eventColor(kind eventKind) lipgloss.Color {
+	switch kind {
+	case eventWatered:
+		return wateredColor
+	case eventFertilized:
+		return fertilizedColor
+	case eventRepotted:
+		return repottedColor
+	case eventChecked:
+		return checkedColor
+	}
+	for _, t := range customTasks {
+		if t.kind == kind {
+			return t.color
+		}
+	}
+	return borderColor
+}
+
+// lookupTask returns the custom task of the given kind.
+func lookupTask(kind string) (careTask, bool) {
+	for _, t := range customTasks {
+		if string(t.kind) == kind {
+			return t, true
+		}
+	}
+	return careTask{}, false
+}
+
+// intervals returns the intervals of the given kind. Custom tasks use the
+// intervals of the plant if it has some, the ones from the config
+// otherwise.
+func (p Plant) intervals(kind eventKind) SeasonalIntervals {
+	switch kind {
+	case eventWatered:
+		return p.WateringIntervals
+	case eventFertilized:
+		return p.FertilizingIntervals
+	}
+	if si, ok := p.TaskIntervals[kind]; ok {
+		return si
+	}
+	if t, ok := lookupTask(string(kind)); ok {
+		return t.intervals
+	}
+	return SeasonalIntervals{}
+}
+
+// setTaskIntervals sets the intervals of a custom task from s, an empty
+// string resets them to the ones from the config.
+func (p *Plant) setTaskIntervals(kind eventKind, s string) error {
+	if s == "" {
+		delete(p.TaskIntervals, kind)
+		return nil
+	}
+	si, err := parseSeasonalIntervals(s)
+	if err != nil {
+		return err
+	}
+	if p.TaskIntervals == nil {
+		p.TaskIntervals = map[eventKind]SeasonalIntervals{}
+	}
+	p.TaskIntervals[kind] = si
+	return nil
+}
+
+func (p Plant) nextScheduledDay(sched *Scheduler, kind eventKind) string {
+	if w, ok := p.scheduled(sched, kind); ok {
+		return w.String()
+	}
+	return "unknown"
+}
+
+// renderTaskStatistics shows the custom tasks in a table.
+func (p Plant) renderTaskStatistics(sched *Scheduler, s table.Styles) string {
+	var rows []table.Row
+	for _, t := range customTasks {
+		times := p.times(t.kind)
+		rows = append(rows, table.Row{
+			string(t.kind), p.intervals(t.kind).String(), p.nextScheduledDay(sched, t.kind),
+			formatTimeInDays(last(times)), formatAverage(average(times, 0)),
+		})
+	}
+	return table.New(
+		table.WithColumns([]table.Column{
+			{Title: "Task", Width: 14},
+			{Title: "Intervals", Width: 10},
+			{Title: "Next", Width: 17},
+			{Title: "Last", Width: 24},
+			{Title: "Avg Interval", Width: 17},
+		}),
+		table.WithRows(rows),
+		table.WithStyles(s),
+		table.WithHeight(len(rows)),
+	).View()
+}
+
+// newTaskIntervalInputs returns an input for the intervals of each custom
+// task, empty if the plant uses the ones from the config.
+func newTaskIntervalInputs(p *Plant) []textinput.Model {
+	var inputs []textinput.Model
+	for _, t := range customTasks {
+		name := string(t.kind)
+		ti := newTextInput(strings.ToUpper(name[:1])+name[1:]+" Intervals", "default "+t.intervals.String())
+		ti.Validate = validateIntervalsInput
+		if si, ok := p.TaskIntervals[t.kind]; ok {
+			ti.SetValue(si.String())
+			ti.Blur()
+		}
+		inputs = append(inputs, ti)
+	}
+	return inputs
+}
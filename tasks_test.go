@@ -0,0 +1,109 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateTasks(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		tasks []Task
+		err   string
+	}{
+		{name: "valid", tasks: []Task{{Name: "misted", Keys: []string{"y"}, Intervals: "3/7"}, {Name: "rotated", Keys: []string{"Y"}}}},
+		{name: "no name", tasks: []Task{{Keys: []string{"y"}}}, err: "name can't be empty"},
+		{name: "builtin", tasks: []Task{{Name: "watered"}}, err: "builtin task"},
+		{name: "defined twice", tasks: []Task{{Name: "misted"}, {Name: "misted"}}, err: "defined twice"},
+		{name: "invalid intervals", tasks: []Task{{Name: "misted", Intervals: "3/7/1"}}, err: "misted"},
+		{name: "builtin key", tasks: []Task{{Name: "misted", Keys: []string{"w"}}}, err: `key "w" is bound already`},
+		{name: "key of another task", tasks: []Task{{Name: "misted", Keys: []string{"y"}}, {Name: "rotated", Keys: []string{"y"}}}, err: `rotated: key "y"`},
+	} {
+		cfg := defaultConfig()
+		cfg.Tasks = tt.tasks
+		err := cfg.validate()
+		if tt.err == "" && err != nil {
+			t.Fatalf("%s: unexpected error %v", tt.name, err)
+		}
+		if tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)) {
+			t.Fatalf("%s: expected an error containing %q, got %v", tt.name, tt.err, err)
+		}
+	}
+}
+
+func TestTaskIntervals(t *testing.T) {
+	applyTasks([]Task{{Name: "misted", Intervals: "3/7"}})
+	t.Cleanup(func() { applyTasks(nil) })
+	configured, _ := parseSeasonalIntervals("3/7")
+	own, _ := parseSeasonalIntervals("2")
+
+	p := Plant{}
+	if got := p.intervals("misted"); got.String() != configured.String() {
+		t.Fatalf("expected the configured intervals, got %s", got)
+	}
+	if err := p.setTaskIntervals("misted", "2"); err != nil {
+		t.Fatal(err)
+	}
+	if got := p.intervals("misted"); got.String() != own.String() {
+		t.Fatalf("expected the plant's intervals, got %s", got)
+	}
+	if err := p.setTaskIntervals("misted", ""); err != nil {
+		t.Fatal(err)
+	}
+	if got := p.intervals("misted"); got.String() != configured.String() {
+		t.Fatalf("expected the configured intervals after a reset, got %s", got)
+	}
+	if got := p.intervals("rotated"); !got.unset() {
+		t.Fatalf("expected no intervals of an unknown task, got %s", got)
+	}
+}
+
+func TestTaskScheduled(t *testing.T) {
+	t.Cleanup(func() { asOf = time.Time{} })
+	applyTasks([]Task{{Name: "misted", Intervals: "3/7"}})
+	t.Cleanup(func() { applyTasks(nil) })
+	sched, err := newScheduler(defaultConfig().Seasons)
+	if err != nil {
+		t.Fatal(err)
+	}
+	asOf = time.Date(2024, 6, 15, 0, 0, 0, 0, time.Local)
+
+	p := Plant{History: []CareEvent{{Kind: "misted", Time: time.Date(2024, 6, 14, 12, 0, 0, 0, time.Local)}}}
+	if w, ok := p.scheduled(sched, "misted"); !ok || w != (window{Opens: 2, Closes: 2}) {
+		t.Fatalf("expected the configured summer interval, got %+v (%v)", w, ok)
+	}
+	if err := p.setTaskIntervals("misted", "1-2"); err != nil {
+		t.Fatal(err)
+	}
+	if w, ok := p.scheduled(sched, "misted"); !ok || w != (window{Opens: 0, Closes: 1}) {
+		t.Fatalf("expected the plant's interval, got %+v (%v)", w, ok)
+	}
+	// watering and other tasks don't count.
+	if w, ok := (Plant{History: p.History}).scheduled(sched, eventWatered); ok {
+		t.Fatalf("expected watering not to be scheduled, got %+v", w)
+	}
+}
+
+func TestFailedEditKeepsTaskIntervals(t *testing.T) {
+	applyTasks([]Task{{Name: "misted", Intervals: "3/7"}})
+	t.Cleanup(func() { applyTasks(nil) })
+	p := &Plant{ID: "0000fred", Name: "Fred"}
+	if err := p.setTaskIntervals("misted", "2"); err != nil {
+		t.Fatal(err)
+	}
+	pDB := &PlantDB{Plants: []*Plant{p}}
+
+	if err := editPlant(pDB, []string{"Fred", "-interval", "misted=5", "-name", ""}); err == nil {
+		t.Fatal("expected an error for an empty name")
+	}
+	if got := p.intervals("misted").String(); got != "2/2" {
+		t.Fatalf("failed edit changed the intervals to %s", got)
+	}
+	if err := editPlant(pDB, []string{"Fred", "-interval", "misted=5"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := p.intervals("misted").String(); got != "5/5" {
+		t.Fatalf("expected the edited intervals, got %s", got)
+	}
+}
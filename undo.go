@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+var (
+	errNothingToUndo = errors.New("nothing to undo")
+	errNothingToRedo = errors.New("nothing to redo")
+)
+
+// history records the state of the plants after every change so that
+// changes can be undone and redone. States are stored the same way as
+// the dirty detection of PlantDB works, as marshaled plants, which
+// covers every kind of mutation without each of them needing an inverse.
+type history struct {
+	// Undo contains the states before each change, the most recent last.
+	Undo [][]byte `json:"undo"`
+	// Redo contains the states that have been undone, the most recent
+	// last.
+	Redo [][]byte `json:"redo"`
+	// Current is the state after the last change.
+	Current []byte `json:"current"`
+
+	// limit is the maximum number of changes that can be undone.
+	limit int
+	// location is where the history is persisted, empty if it's only
+	// kept for the session.
+	location string
+}
+
+// newHistory starts recording the changes of pDB. If location is set,
+// the history from the last session is restored, unless the DB has been
+// changed outside of the UI since.
+func newHistory(pDB *PlantDB, limit int, location string) (*history, error) {
+	h := &history{limit: limit, location: location}
+	h.reset(pDB)
+	if location == "" {
+		return h, nil
+	}
+
+	data, err := os.ReadFile(location)
+	if os.IsNotExist(err) {
+		return h, nil
+	} else if err != nil {
+		return h, fmt.Errorf("could not read undo history: %w", err)
+	}
+	var stored history
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return h, fmt.Errorf("malformatted undo history: %w", err)
+	}
+	if bytes.Equal(stored.Current, h.Current) {
+		h.Undo, h.Redo = stored.Undo, stored.Redo
+	}
+	return h, nil
+}
+
+// snapshot returns the current state of the plants.
+func (pDB *PlantDB) snapshot() []byte {
+	pDB.normalise()
+	// the plants have just been loaded from JSON, this can't fail.
+	data, _ := json.Marshal(pDB.Plants)
+	return data
+}
+
+// reset forgets all changes, e.g. after the plants have been replaced
+// with the ones from the storage.
+func (h *history) reset(pDB *PlantDB) {
+	h.Undo, h.Redo = nil, nil
+	h.Current = pDB.snapshot()
+}
+
+// record adds the current state of the plants to the history if they
+// have changed since the last call.
+func (h *history) record(pDB *PlantDB) {
+	s := pDB.snapshot()
+	if bytes.Equal(s, h.Current) {
+		return
+	}
+	h.Undo = append(h.Undo, h.Current)
+	if len(h.Undo) > h.limit {
+		h.Undo = h.Undo[len(h.Undo)-h.limit:]
+	}
+	h.Redo = nil
+	h.Current = s
+}
+
+func (h *history) undo(pDB *PlantDB) error {
+	if len(h.Undo) == 0 {
+		return errNothingToUndo
+	}
+	return h.move(pDB, &h.Undo, &h.Redo)
+}
+
+func (h *history) redo(pDB *PlantDB) error {
+	if len(h.Redo) == 0 {
+		return errNothingToRedo
+	}
+	return h.move(pDB, &h.Redo, &h.Undo)
+}
+
+// move restores the last state of from, and pushes the current state
+// onto to.
+func (h *history) move(pDB *PlantDB, from, to *[][]byte) error {
+	state := (*from)[len(*from)-1]
+	var plants []*Plant
+	if err := json.Unmarshal(state, &plants); err != nil {
+		return fmt.Errorf("malformatted undo history: %w", err)
+	}
+
+	*from = (*from)[:len(*from)-1]
+	*to = append(*to, h.Current)
+	h.Current = state
+	pDB.Plants = plants
+	return nil
+}
+
+// save persists the history, if enabled.
+func (h *history) save() error {
+	if h.location == "" {
+		return nil
+	}
+	data, err := json.Marshal(h)
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(h.location, data, 0600); err != nil {
+		return fmt.Errorf("could not save undo history: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestHistory(t *testing.T) {
+	pDB := &PlantDB{Plants: []*Plant{{Name: "a"}}}
+	h, err := newHistory(pDB, 2, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"b", "c", "d"} {
+		pDB.Plants[0].Name = name
+		h.record(pDB)
+	}
+	// recording without changes is a no-op.
+	h.record(pDB)
+
+	for _, expected := range []string{"c", "b"} {
+		if err := h.undo(pDB); err != nil {
+			t.Fatal(err)
+		}
+		if pDB.Plants[0].Name != expected {
+			t.Fatalf("wrong state after undo. expected=%v, got=%v", expected, pDB.Plants[0].Name)
+		}
+	}
+	// the limit is reached, "a" is gone.
+	if err := h.undo(pDB); !errors.Is(err, errNothingToUndo) {
+		t.Fatalf("expected errNothingToUndo, got %v", err)
+	}
+
+	if err := h.redo(pDB); err != nil {
+		t.Fatal(err)
+	}
+	if pDB.Plants[0].Name != "c" {
+		t.Fatalf("wrong state after redo. expected=c, got=%v", pDB.Plants[0].Name)
+	}
+	// a new change drops what could be redone.
+	pDB.Plants[0].History = []CareEvent{{Time: time.Now(), Kind: eventWatered}}
+	h.record(pDB)
+	if err := h.redo(pDB); !errors.Is(err, errNothingToRedo) {
+		t.Fatalf("expected errNothingToRedo, got %v", err)
+	}
+}
+
+func TestHistoryPersisted(t *testing.T) {
+	location := filepath.Join(t.TempDir(), "db.json.undo")
+	pDB := &PlantDB{Plants: []*Plant{{Name: "a"}}}
+	h, _ := newHistory(pDB, 10, location)
+	pDB.Plants[0].Name = "b"
+	h.record(pDB)
+	if err := h.save(); err != nil {
+		t.Fatal(err)
+	}
+
+	h, err := newHistory(pDB, 10, location)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := h.undo(pDB); err != nil {
+		t.Fatalf("history has not been restored: %v", err)
+	}
+
+	// the history is discarded if the DB has been changed elsewhere.
+	pDB.Plants[0].Name = "c"
+	h, _ = newHistory(pDB, 10, location)
+	if err := h.undo(pDB); !errors.Is(err, errNothingToUndo) {
+		t.Fatalf("expected errNothingToUndo, got %v", err)
+	}
+}
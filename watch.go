@@ -0,0 +1,206 @@
+package main
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// dbCheckInterval is how often the UI checks the DB for changes made
+// by other instances.
+const dbCheckInterval = 2 * time.Second
+
+type dbCheckMsg struct{}
+
+func watchDB() tea.Cmd {
+	return tea.Tick(dbCheckInterval, func(time.Time) tea.Msg {
+		return dbCheckMsg{}
+	})
+}
+
+// checkDB reloads the DB if it has been changed by someone else. If there
+// are local changes that haven't been saved, the user is asked what to do.
+func (sp *ShowPlants) checkDB() {
+	changed, err := sp.PlantDB.storage.Modified()
+	if err != nil {
+		sp.err = err
+		return
+	}
+	// errors of earlier saves stay until a save succeeds.
+	if !changed {
+		return
+	}
+
+	if sp.PlantDB.dirty() {
+		sp.handleConflict(errDBModified)
+		return
+	}
+	sp.err = sp.PlantDB.reload()
+	sp.history.reset(sp.PlantDB)
+	sp.refreshItems()
+}
+
+// handleConflict opens the conflict prompt if err is errDBModified and
+// the user isn't busy with another prompt.
+func (sp *ShowPlants) handleConflict(err error) {
+	sp.err = err
+	if !errors.Is(err, errDBModified) || sp.prompt != nil {
+		return
+	}
+	sp.prompt = &conflictPrompt{sp: sp}
+}
+
+// conflictPrompt asks the user how to resolve changes that have been
+// made both locally and by someone else.
+type conflictPrompt struct {
+	sp *ShowPlants
+}
+
+func (cp *conflictPrompt) Init() tea.Cmd { return nil }
+
+func (cp *conflictPrompt) View() string {
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("DB Changed") + ":\n\n")
+	b.WriteString("Another instance has modified the DB,\n")
+	b.WriteString("but there are changes that haven't been saved.\n\n")
+	b.WriteString("k - keep mine, overwrite theirs\n")
+	b.WriteString("r - reload theirs, discard mine\n")
+	b.WriteString("m - merge both\n")
+	return b.String()
+}
+
+func (cp *conflictPrompt) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return cp, nil
+	}
+
+	pDB := cp.sp.PlantDB
+	var err error
+	switch keyMsg.String() {
+	case "ctrl+c":
+		return cp, tea.Quit
+	case "esc":
+		return nil, nil
+	case "k":
+		err = pDB.save(true)
+	case "r":
+		err = pDB.reload()
+		cp.sp.history.reset(pDB)
+	case "m":
+		err = pDB.mergeFromStorage()
+		cp.sp.history.reset(pDB)
+	default:
+		return cp, nil
+	}
+
+	cp.sp.err = err
+	cp.sp.refreshItems()
+	return nil, nil
+}
+
+// mergeFromStorage merges the stored plants into the DB and saves the
+// result.
+func (pDB *PlantDB) mergeFromStorage() error {
+	stored, err := pDB.storage.Load()
+	if err != nil {
+		return err
+	}
+	pDB.Plants = mergePlants(pDB.Plants, stored.Plants)
+	return pDB.Save()
+}
+
+// mergePlants matches plants by ID, or by name for plants that got
+// different IDs when migrating. The events of matching plants are
+// combined, all other fields are taken from mine. Plants that only exist
+// on one side are kept.
+func mergePlants(mine, theirs []*Plant) []*Plant {
+	merged := make([]*Plant, 0, len(theirs))
+	used := make([]bool, len(mine))
+	find := func(same func(p *Plant) bool) int {
+		for i, p := range mine {
+			if !used[i] && same(p) {
+				return i
+			}
+		}
+		return -1
+	}
+	for _, t := range theirs {
+		match := find(func(p *Plant) bool { return p.ID == t.ID })
+		if match < 0 {
+			match = find(func(p *Plant) bool {
+				return p.Name == t.Name && !containsID(theirs, p.ID)
+			})
+		}
+		if match < 0 {
+			merged = append(merged, t)
+			continue
+		}
+
+		used[match] = true
+		p := mine[match]
+		p.History = mergeEvents(p.History, t.History)
+		p.Reminders = mergeReminders(p.Reminders, t.Reminders)
+		merged = append(merged, p)
+	}
+
+	for i, p := range mine {
+		if !used[i] {
+			merged = append(merged, p)
+		}
+	}
+	return merged
+}
+
+func containsID(plants []*Plant, id string) bool {
+	for _, p := range plants {
+		if p.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeEvents adds all events of theirs that aren't on the same day as
+// an event of the same kind (and task) in mine.
+func mergeEvents(mine, theirs []CareEvent) []CareEvent {
+	merged := append([]CareEvent(nil), mine...)
+outer:
+	for _, t := range theirs {
+		for _, m := range mine {
+			if m.Kind == t.Kind && m.Task == t.Task && sameDay(m.Time, t.Time) {
+				continue outer
+			}
+		}
+		merged = append(merged, t)
+	}
+
+	sortEvents(merged)
+	return merged
+}
+
+// mergeReminders merges reminders with the same text and due day, they
+// are done if they have been completed on either side.
+func mergeReminders(mine, theirs []Reminder) []Reminder {
+	merged := append([]Reminder(nil), mine...)
+outer:
+	for _, t := range theirs {
+		for i, m := range merged {
+			if m.Text == t.Text && sameDay(m.Due, t.Due) {
+				if !m.done() && t.done() {
+					merged[i].DoneAt = t.DoneAt
+				}
+				continue outer
+			}
+		}
+		merged = append(merged, t)
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].Due.Before(merged[j].Due)
+	})
+	return merged
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMergePlants(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2024, 6, d, 12, 0, 0, 0, time.Local)
+	}
+	mine := []*Plant{
+		{ID: "a", Name: "Fred", History: []CareEvent{{Kind: eventWatered, Time: day(1)}}},
+		{ID: "b", Name: "Bob"},
+		// got another ID when migrating on the other side.
+		{ID: "d1", Name: "Dora", History: []CareEvent{{Kind: eventWatered, Time: day(2)}}},
+	}
+	theirs := []*Plant{
+		{ID: "a", Name: "Freddy", History: []CareEvent{
+			{Kind: eventWatered, Time: day(1).Add(time.Hour)},
+			{Kind: eventFertilized, Time: day(2)},
+		}},
+		{ID: "c", Name: "Cleo"},
+		{ID: "d2", Name: "Dora", History: []CareEvent{{Kind: eventWatered, Time: day(3)}}},
+	}
+
+	merged := mergePlants(mine, theirs)
+	var ids []string
+	for _, p := range merged {
+		ids = append(ids, p.ID)
+	}
+	if want := []string{"a", "c", "d1", "b"}; !reflect.DeepEqual(ids, want) {
+		t.Fatalf("wrong plants. expected=%v, got=%v", want, ids)
+	}
+	if merged[0].Name != "Fred" {
+		t.Fatalf("expected my name to be kept, got %s", merged[0].Name)
+	}
+	if n := len(merged[0].History); n != 2 {
+		t.Fatalf("expected 2 events of Fred, got %+v", merged[0].History)
+	}
+	if n := len(merged[2].History); n != 2 {
+		t.Fatalf("expected 2 events of Dora, got %+v", merged[2].History)
+	}
+}
+
+func TestMergeEvents(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2024, 6, d, 12, 0, 0, 0, time.Local)
+	}
+	mine := []CareEvent{
+		{Kind: eventWatered, Time: day(1), Amount: "mine"},
+		{Kind: eventSnoozed, Task: eventWatered, Time: day(2), Days: 1},
+	}
+	theirs := []CareEvent{
+		{Kind: eventWatered, Time: day(1).Add(time.Hour), Amount: "theirs"},
+		{Kind: eventSnoozed, Task: eventFertilized, Time: day(2), Days: 1},
+		{Kind: eventWatered, Time: day(3)},
+	}
+
+	merged := mergeEvents(mine, theirs)
+	if len(merged) != 4 {
+		t.Fatalf("expected 4 events, got %+v", merged)
+	}
+	if merged[0].Amount != "mine" {
+		t.Fatalf("expected my event to be kept on the same day, got %+v", merged[0])
+	}
+	for i := 1; i < len(merged); i++ {
+		if merged[i].Time.Before(merged[i-1].Time) {
+			t.Fatalf("events are not sorted: %+v", merged)
+		}
+	}
+}
+
+func TestMergeReminders(t *testing.T) {
+	due := time.Date(2024, 6, 10, 0, 0, 0, 0, time.Local)
+	done := due.Add(time.Hour)
+	mine := []Reminder{{Text: "repot", Due: due}}
+	theirs := []Reminder{
+		{Text: "repot", Due: due.Add(time.Hour), DoneAt: &done},
+		{Text: "mist", Due: due.AddDate(0, 0, -3)},
+	}
+
+	merged := mergeReminders(mine, theirs)
+	if len(merged) != 2 || merged[0].Text != "mist" || merged[1].Text != "repot" {
+		t.Fatalf("wrong reminders: %+v", merged)
+	}
+	if !merged[1].done() {
+		t.Fatal("expected the reminder to be done, as it has been completed by them")
+	}
+}
+
+func TestJSONSaveDetectsExternalWrite(t *testing.T) {
+	location := filepath.Join(t.TempDir(), "plants.json")
+	pDB, err := openDB(location, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pDB.Plants = []*Plant{{ID: "0000fred", Name: "Fred"}}
+	if err := pDB.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	other, err := openDB(location, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other.Plants = append(other.Plants, &Plant{ID: "00000bob", Name: "Bob"})
+	if err := other.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	pDB.Plants[0].Location = "kitchen"
+	if err := pDB.Save(); !errors.Is(err, errDBModified) {
+		t.Fatalf("expected errDBModified, got %v", err)
+	}
+
+	// the UI keeps the error of the failed save, but asks what to do.
+	sp := &ShowPlants{PlantDB: pDB, err: errors.New("disk full")}
+	sp.checkDB()
+	if !errors.Is(sp.err, errDBModified) || sp.prompt == nil {
+		t.Fatalf("expected a conflict, got %v", sp.err)
+	}
+	if err := pDB.mergeFromStorage(); err != nil {
+		t.Fatal(err)
+	}
+	if len(pDB.Plants) != 2 || pDB.Plants[0].Location != "kitchen" {
+		t.Fatalf("wrong plants after merging: %+v", pDB.Plants)
+	}
+}
+
+func TestCheckDBKeepsSaveError(t *testing.T) {
+	pDB, err := openDB(filepath.Join(t.TempDir(), "plants.json"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pDB.Plants = []*Plant{{ID: "0000fred", Name: "Fred"}}
+	if err := pDB.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	saveErr := errors.New("could not write DB file")
+	sp := &ShowPlants{PlantDB: pDB, err: saveErr}
+	sp.checkDB()
+	if sp.err != saveErr {
+		t.Fatalf("expected the save error to be kept, got %v", sp.err)
+	}
+}